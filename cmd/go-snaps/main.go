@@ -0,0 +1,338 @@
+/*
+Command go-snaps manages snapshot files without running the tests.
+
+	go-snaps <command> [flags] [paths...]
+
+Paths are snapshot files or directories searched recursively, defaulting to the current directory.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+)
+
+const usage = `Usage: go-snaps <command> [flags] [paths...]
+
+Commands:
+  list     list the tests and snapshots of every snapshot file
+  accept   accept the pending snapshots
+  reject   reject the pending snapshots
+  sort     sort the snapshots of every snapshot file
+  check    validate the syntax of every snapshot file
+  prune    remove the snapshots of the tests matching --test, except frozen ones
+  review   review the pending snapshots one by one, accepting or skipping them
+  apply    apply the received snapshots of an artifact directory: apply <artifact dir> [module root]
+
+Paths are snapshot files or directories searched recursively, defaulting to the current directory.
+`
+
+var errUsage = errors.New("usage")
+
+// cli is the standard input and output of the command.
+type cli struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+type command func(c *cli, args []string) error
+
+var commands = map[string]command{
+	"list":   list,
+	"accept": accept,
+	"reject": reject,
+	"sort":   sortFiles,
+	"check":  check,
+	"prune":  prune,
+	"review": review,
+	"apply":  apply,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+func run(args []string, c *cli) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(c.stderr, usage)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.stderr, "go-snaps: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := cmd(c, args[1:]); err != nil {
+		fmt.Fprintf(c.stderr, "go-snaps: %s\n", err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(c.stderr, "\n"+usage)
+			return 2
+		}
+
+		return 1
+	}
+
+	return 0
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return nil, fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if flags.NArg() == 0 {
+		return []string{"."}, nil
+	}
+
+	return flags.Args(), nil
+}
+
+// snapshotFiles returns the snapshot files inside paths, excluding pending snapshots and blobs.
+func snapshotFiles(paths []string) ([]string, error) {
+	files := []string{}
+
+	for _, path := range paths {
+		path = strings.TrimSuffix(path, "...")
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == "blobs" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if strings.Contains(d.Name(), ".snap") && !strings.HasSuffix(d.Name(), ".snap.new") &&
+				!strings.HasSuffix(d.Name(), ".snap.lock") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(files)
+
+	return slices.Compact(files), nil
+}
+
+func list(c *cli, args []string) error {
+	paths, err := parseFlags(flag.NewFlagSet("list", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	files, err := snapshotFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		snapshots, err := snaps.ReadSnapshots(file)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			fmt.Fprintf(c.stdout, "%s (standalone)\n", file)
+			continue
+		}
+
+		fmt.Fprintln(c.stdout, file)
+
+		// snapshots grouped by test, in the order the tests first appear
+		tests := []string{}
+		byTest := map[string][]snaps.FileSnapshot{}
+		for _, s := range snapshots {
+			test := testName(s.ID)
+			if _, ok := byTest[test]; !ok {
+				tests = append(tests, test)
+			}
+			byTest[test] = append(byTest[test], s)
+		}
+
+		for _, test := range tests {
+			fmt.Fprintf(c.stdout, "  %s (%d)\n", test, len(byTest[test]))
+			for _, s := range byTest[test] {
+				fmt.Fprintf(c.stdout, "    [%s] line %d\n", s.ID, s.Line)
+			}
+		}
+	}
+
+	return nil
+}
+
+func testName(id string) string {
+	if i := strings.LastIndex(id, " - "); i != -1 {
+		return id[:i]
+	}
+
+	return id
+}
+
+func accept(c *cli, args []string) error {
+	return applyPending(c, "accept", snaps.AcceptPending, args)
+}
+
+func reject(c *cli, args []string) error {
+	return applyPending(c, "reject", snaps.RejectPending, args)
+}
+
+func applyPending(c *cli, name string, fn func(string) error, args []string) error {
+	paths, err := parseFlags(flag.NewFlagSet(name, flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		files, err := snaps.PendingFiles(strings.TrimSuffix(path, "..."))
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			if err := fn(file); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.stdout, "%sed %s\n", name, file)
+		}
+	}
+
+	return nil
+}
+
+func sortFiles(_ *cli, args []string) error {
+	paths, err := parseFlags(flag.NewFlagSet("sort", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	files, err := snapshotFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := snaps.SortFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func check(c *cli, args []string) error {
+	paths, err := parseFlags(flag.NewFlagSet("check", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	files, err := snapshotFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	var invalid int
+	for _, file := range files {
+		if err := snaps.CheckFile(file); err != nil {
+			fmt.Fprintln(c.stdout, err)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d invalid snapshot files", invalid)
+	}
+
+	return nil
+}
+
+func prune(c *cli, args []string) error {
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	test := flags.String("test", "", "remove the snapshots of the tests with names matching the `regex`")
+	paths, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *test == "" {
+		return fmt.Errorf("%w: prune requires --test", errUsage)
+	}
+
+	re, err := regexp.Compile(*test)
+	if err != nil {
+		return err
+	}
+
+	files, err := snapshotFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		removed, err := snaps.PruneFile(file, re)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range removed {
+			fmt.Fprintf(c.stdout, "removed [%s] from %s\n", id, file)
+		}
+	}
+
+	return nil
+}
+
+func apply(c *cli, args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	root := "."
+	switch flags.NArg() {
+	case 1:
+	case 2:
+		root = flags.Arg(1)
+	default:
+		return fmt.Errorf("%w: apply requires an artifact directory", errUsage)
+	}
+
+	dir := flags.Arg(0)
+	files, err := snaps.PendingFiles(dir)
+	if err != nil {
+		return err
+	}
+	if err := snaps.ApplyArtifacts(dir, root); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		rel, _ := filepath.Rel(dir, file)
+		fmt.Fprintf(c.stdout, "applied %s\n", filepath.Join(root, strings.TrimSuffix(rel, ".new")))
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func setupDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		test.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		test.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+func runCmd(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &cli{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr})
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Run("should print usage", func(t *testing.T) {
+		code, _, stderr := runCmd(t)
+
+		test.Equal(t, 2, code)
+		test.Equal(t, usage, stderr)
+	})
+
+	t.Run("should reject unknown command", func(t *testing.T) {
+		code, _, stderr := runCmd(t, "unknown")
+
+		test.Equal(t, 2, code)
+		test.Contains(t, stderr, `unknown command "unknown"`)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		dir := setupDir(t, map[string]string{
+			"__snapshots__/a_test.snap":     "\n[TestA - 1]\na\n---\n\n[TestB - 1]\nb\n---\n\n[TestA - 2]\na\n---\n",
+			"__snapshots__/TestC_1.snap":    "standalone",
+			"__snapshots__/a_test.snap.new": "# format: 2\n# pending: shared\n",
+			"__snapshots__/blobs/x.snap":    "blob",
+		})
+
+		code, stdout, _ := runCmd(t, "list", dir)
+
+		test.Equal(t, 0, code)
+		test.Equal(
+			t,
+			filepath.Join(dir, "__snapshots__", "TestC_1.snap")+" (standalone)\n"+
+				filepath.Join(dir, "__snapshots__", "a_test.snap")+"\n"+
+				"  TestA (2)\n    [TestA - 1] line 2\n    [TestA - 2] line 10\n"+
+				"  TestB (1)\n    [TestB - 1] line 6\n",
+			stdout,
+		)
+	})
+
+	t.Run("accept and reject", func(t *testing.T) {
+		dir := setupDir(t, map[string]string{
+			"a.snap":         "\n[TestA - 1]\nold\n---\n",
+			"a.snap.new":     "# format: 2\n# pending: shared\n\n[TestA - 1]\nnew\n---\n",
+			"sub/b.snap.new": "# format: 2\n# pending: standalone\n\n[TestB - standalone]\nb\n---\n",
+		})
+
+		code, stdout, _ := runCmd(t, "accept", filepath.Join(dir, "a.snap.new"))
+
+		test.Equal(t, 0, code)
+		test.Equal(t, "accepted "+filepath.Join(dir, "a.snap.new")+"\n", stdout)
+		test.Equal(t, "\n[TestA - 1]\nnew\n---\n", test.GetFileContent(t, filepath.Join(dir, "a.snap")))
+
+		code, stdout, _ = runCmd(t, "reject", dir+"/...")
+
+		test.Equal(t, 0, code)
+		test.Equal(t, "rejected "+filepath.Join(dir, "sub", "b.snap.new")+"\n", stdout)
+		_, err := os.Stat(filepath.Join(dir, "sub", "b.snap"))
+		test.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		dir := setupDir(t, map[string]string{
+			"a.snap": "\n[TestA - 10]\n10\n---\n\n[TestA - 2]\n2\n---\n",
+		})
+
+		code, _, _ := runCmd(t, "sort", dir)
+
+		test.Equal(t, 0, code)
+		test.Equal(
+			t,
+			"\n[TestA - 2]\n2\n---\n\n[TestA - 10]\n10\n---\n",
+			test.GetFileContent(t, filepath.Join(dir, "a.snap")),
+		)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		dir := setupDir(t, map[string]string{
+			"a.snap": "\n[TestA - 1]\na\n---\n",
+			"b.snap": "\n[TestB - 1]\nb\n",
+		})
+
+		code, stdout, stderr := runCmd(t, "check", dir)
+
+		test.Equal(t, 1, code)
+		test.Equal(
+			t,
+			filepath.Join(dir, "b.snap")+`:2: snapshot [TestB - 1] is missing the end sequence "---"`+"\n",
+			stdout,
+		)
+		test.Equal(t, "go-snaps: 1 invalid snapshot files\n", stderr)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		dir := setupDir(t, map[string]string{
+			"a.snap": "\n[TestOld - 1]\nold\n---\n\n[TestA - 1]\na\n---\n",
+		})
+
+		code, stdout, _ := runCmd(t, "prune", "--test", "^TestOld$", dir)
+
+		test.Equal(t, 0, code)
+		test.Equal(t, "removed [TestOld - 1] from "+filepath.Join(dir, "a.snap")+"\n", stdout)
+		test.Equal(t, "\n[TestA - 1]\na\n---\n", test.GetFileContent(t, filepath.Join(dir, "a.snap")))
+	})
+
+	t.Run("should not rewrite standalone snapshot files", func(t *testing.T) {
+		standalone := "[server]\nport = 80\n---\nmore"
+		dir := setupDir(t, map[string]string{
+			"TestConfig_1.snap": standalone,
+			"TestServer_1.snap": "[TestB - 1]\nb\n---\n[TestA - 1]\na\n",
+		})
+
+		for _, args := range [][]string{{"sort", dir}, {"prune", "--test", ".", dir}} {
+			code, stdout, _ := runCmd(t, args...)
+
+			test.Equal(t, 0, code)
+			test.Equal(t, "", stdout)
+			test.Equal(t, standalone, test.GetFileContent(t, filepath.Join(dir, "TestConfig_1.snap")))
+			test.Equal(
+				t,
+				"[TestB - 1]\nb\n---\n[TestA - 1]\na\n",
+				test.GetFileContent(t, filepath.Join(dir, "TestServer_1.snap")),
+			)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		root := setupDir(t, map[string]string{
+			"pkg/__snapshots__/a_test.snap": "\n[TestA - 1]\nold\n---\n",
+		})
+		artifacts := setupDir(t, map[string]string{
+			"pkg/__snapshots__/a_test.snap.new": "# format: 2\n# pending: shared\n\n[TestA - 1]\nnew\n---\n",
+		})
+
+		code, stdout, _ := runCmd(t, "apply", artifacts, root)
+
+		test.Equal(t, 0, code)
+		test.Equal(t, "applied "+filepath.Join(root, "pkg", "__snapshots__", "a_test.snap")+"\n", stdout)
+		test.Equal(
+			t,
+			"\n[TestA - 1]\nnew\n---\n",
+			test.GetFileContent(t, filepath.Join(root, "pkg", "__snapshots__", "a_test.snap")),
+		)
+	})
+
+	t.Run("prune requires test", func(t *testing.T) {
+		code, _, stderr := runCmd(t, "prune", t.TempDir())
+
+		test.Equal(t, 2, code)
+		test.Contains(t, stderr, "prune requires --test")
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+)
+
+var errNotTerminal = errors.New("review needs an interactive terminal, use accept or reject instead")
+
+// isTerminal reports whether r is a terminal, as reviewing needs to prompt the user.
+var isTerminal = func(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type answer byte
+
+const (
+	acceptAnswer    answer = 'y'
+	skipAnswer      answer = 's'
+	acceptAllAnswer answer = 'a'
+	quitAnswer      answer = 'q'
+)
+
+// review shows the diff of every pending snapshot and prompts whether to accept it.
+//
+// Pending snapshots are reviewed out of the `go test` process, as it doesn't forward the standard input to tests.
+func review(c *cli, args []string) error {
+	paths, err := parseFlags(flag.NewFlagSet("review", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	if !isTerminal(c.stdin) {
+		return errNotTerminal
+	}
+
+	in := bufio.NewReader(c.stdin)
+	var accepted, skipped int
+	defer func() {
+		fmt.Fprintf(c.stdout, "\naccepted %d, skipped %d snapshots\n", accepted, skipped)
+	}()
+
+	for _, path := range paths {
+		files, err := snaps.PendingFiles(strings.TrimSuffix(path, "..."))
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			pending, err := snaps.ReadPending(file)
+			if err != nil {
+				return err
+			}
+
+			acceptAll := false
+			for i, p := range pending {
+				if !acceptAll {
+					printPending(c.stdout, p)
+
+					switch prompt(in, c.stdout) {
+					case skipAnswer:
+						skipped++
+						continue
+					case acceptAllAnswer:
+						acceptAll = true
+					case quitAnswer:
+						skipped += len(pending) - i
+						return nil
+					}
+				}
+
+				if err := snaps.AcceptPendingSnapshot(p); err != nil {
+					return err
+				}
+				fmt.Fprintf(c.stdout, "accepted [%s] in %s\n", p.ID, p.Target)
+				accepted++
+			}
+		}
+	}
+
+	return nil
+}
+
+func printPending(w io.Writer, p snaps.PendingSnapshot) {
+	fmt.Fprintf(w, "\n[%s] in %s\n", p.ID, p.Target)
+	if p.New {
+		fmt.Fprintf(w, "\nnew snapshot:\n%s\n\n", p.Snapshot)
+		return
+	}
+
+	fmt.Fprintln(w, p.Diff)
+}
+
+// prompt asks until a valid answer is given, quitting if the input ends.
+func prompt(in *bufio.Reader, w io.Writer) answer {
+	for {
+		fmt.Fprint(w, "accept snapshot? [y]es, [s]kip, [a]ccept all in file, [q]uit: ")
+
+		line, err := in.ReadString('\n')
+		if line = strings.ToLower(strings.TrimSpace(line)); line != "" {
+			switch a := answer(line[0]); a {
+			case acceptAnswer, skipAnswer, acceptAllAnswer, quitAnswer:
+				return a
+			case 'n':
+				return skipAnswer
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(w)
+			return quitAnswer
+		}
+	}
+}
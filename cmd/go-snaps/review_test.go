@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func TestReview(t *testing.T) {
+	t.Run("should require a terminal", func(t *testing.T) {
+		code, _, stderr := runCmd(t, "review", t.TempDir())
+
+		test.Equal(t, 1, code)
+		test.Contains(t, stderr, errNotTerminal.Error())
+	})
+
+	t.Run("should prompt for every pending snapshot", func(t *testing.T) {
+		isTerminalPrev := isTerminal
+		isTerminal = func(io.Reader) bool { return true }
+		t.Cleanup(func() { isTerminal = isTerminalPrev })
+
+		dir := setupDir(t, map[string]string{
+			"a.snap": "\n[TestA - 1]\nold\n---\n",
+			"a.snap.new": "# format: 2\n# pending: shared\n\n[TestA - 1]\nnew\n---\n" +
+				"\n[TestB - 1]\nb\n---\n\n[TestC - 1]\nc\n---\n\n[TestD - 1]\nd\n---\n",
+			"b.snap.new": "# format: 2\n# pending: shared\n\n[TestE - 1]\ne\n---\n",
+		})
+		var stdout, stderr bytes.Buffer
+
+		// invalid answers are asked again, and the input ending quits
+		code := run(
+			[]string{"review", dir},
+			&cli{stdin: strings.NewReader("y\nx\ns\na\n"), stdout: &stdout, stderr: &stderr},
+		)
+
+		test.Equal(t, 0, code)
+		test.Equal(t, "", stderr.String())
+		test.Contains(t, stdout.String(), "[TestA - 1] in "+filepath.Join(dir, "a.snap"))
+		test.Contains(t, stdout.String(), "new snapshot:\nb\n")
+		test.Contains(t, stdout.String(), "accepted [TestD - 1] in "+filepath.Join(dir, "a.snap"))
+		test.Contains(t, stdout.String(), "accepted 3, skipped 2 snapshots\n")
+		test.Equal(
+			t,
+			"\n[TestA - 1]\nnew\n---\n\n[TestC - 1]\nc\n---\n\n[TestD - 1]\nd\n---\n",
+			test.GetFileContent(t, filepath.Join(dir, "a.snap")),
+		)
+		// skipped snapshots are still pending
+		test.Equal(
+			t,
+			"# format: 2\n# pending: shared\n\n[TestB - 1]\nb\n---\n",
+			test.GetFileContent(t, filepath.Join(dir, "a.snap.new")),
+		)
+		_, err := os.Stat(filepath.Join(dir, "b.snap.new"))
+		test.NoError(t, err)
+	})
+}
@@ -0,0 +1,35 @@
+package examples
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/match"
+	"github.com/gkampitakis/go-snaps/snaps"
+)
+
+func TestMatchJSONLines(t *testing.T) {
+	t.Run("should make a json lines snapshot", func(t *testing.T) {
+		events := strings.NewReader(`{"event":"user.created","user":"mock-user"}
+{"event":"user.updated","user":"mock-user","fields":["email"]}
+{"event":"user.deleted","user":"mock-user"}
+`)
+
+		snaps.MatchJSONLines(t, events)
+	})
+
+	t.Run("should apply matchers on every record", func(t *testing.T) {
+		events := `{"id":"8a2f","event":"order.created","created":"2024-01-01T10:00:00Z"}
+{"id":"9b3e","event":"order.paid","created":"2024-01-01T10:05:00Z"}`
+
+		snaps.MatchJSONLines(
+			t,
+			events,
+			match.Any("created"),
+			match.CustomRecord("id", func(record int, val any) (any, error) {
+				return fmt.Sprintf("<event id %d>", record), nil
+			}),
+		)
+	})
+}
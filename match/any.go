@@ -48,6 +48,11 @@ func (a *anyMatcher) Placeholder(p any) *anyMatcher {
 	return a
 }
 
+// String returns the matcher as written in code e.g. match.Any("user.name")
+func (a anyMatcher) String() string {
+	return "match." + a.name + "(" + quotePaths(a.paths) + ")"
+}
+
 // ErrOnMissingPath determines if matcher will fail in case of trying to access a path
 // that doesn't exist
 func (a *anyMatcher) ErrOnMissingPath(e bool) *anyMatcher {
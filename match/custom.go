@@ -12,6 +12,7 @@ import (
 
 type customMatcher struct {
 	callback         func(val any) (any, error)
+	recordCallback   func(record int, val any) (any, error)
 	errOnMissingPath bool
 	name             string
 	path             string
@@ -65,6 +66,32 @@ func Custom(path string, callback CustomCallback) *customMatcher {
 	}
 }
 
+type CustomRecordCallback func(record int, val any) (any, error)
+
+/*
+CustomRecord is a Custom matcher whose callback also receives the number of the record
+it is applied on. It is intended to be used with snaps.MatchJSONLines where matchers
+are applied on every record of the stream. Records are numbered from 1, skipping empty lines,
+the same as in the failure messages of snaps.MatchJSONLines.
+
+	match.CustomRecord("id", func(record int, val any) (any, error) {
+		return fmt.Sprintf("<id of record %d>", record), nil
+	})
+
+When used outside of snaps.MatchJSONLines the record number is always 0.
+*/
+func CustomRecord(path string, callback CustomRecordCallback) *customMatcher {
+	return &customMatcher{
+		errOnMissingPath: true,
+		callback: func(val any) (any, error) {
+			return callback(0, val)
+		},
+		recordCallback: callback,
+		name:           "Custom",
+		path:           path,
+	}
+}
+
 // ErrOnMissingPath determines if Matcher will fail in case of trying to access a json path
 // that doesn't exist
 func (c *customMatcher) ErrOnMissingPath(e bool) *customMatcher {
@@ -72,6 +99,11 @@ func (c *customMatcher) ErrOnMissingPath(e bool) *customMatcher {
 	return c
 }
 
+// String returns the matcher as written in code, without the callback e.g. match.Custom("user.age")
+func (c *customMatcher) String() string {
+	return "match." + c.name + "(" + quotePaths([]string{c.path}) + ")"
+}
+
 // YAML is intended to be called internally on snaps.MatchYAML for applying Custom matcher
 func (c *customMatcher) YAML(b []byte) ([]byte, []MatcherError) {
 	f, err := parser.ParseBytes(b, parser.ParseComments)
@@ -126,6 +158,21 @@ func (c *customMatcher) JSON(b []byte) ([]byte, []MatcherError) {
 	return json, errs
 }
 
+// JSONRecord is intended to be called internally on snaps.MatchJSONLines for applying Custom matcher
+// on the record with the given index
+func (c *customMatcher) JSONRecord(b []byte, record int) ([]byte, []MatcherError) {
+	if c.recordCallback == nil {
+		return c.JSON(b)
+	}
+
+	rc := *c
+	rc.callback = func(val any) (any, error) {
+		return c.recordCallback(record, val)
+	}
+
+	return rc.JSON(b)
+}
+
 func (c *customMatcher) processPathJSON(json []byte, path string) ([]byte, error) {
 	r := gjson.GetBytes(json, path)
 	if !r.Exists() {
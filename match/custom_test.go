@@ -2,6 +2,7 @@ package match
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/gkampitakis/go-snaps/internal/test"
@@ -201,6 +202,44 @@ func TestCustomMatcher(t *testing.T) {
 		})
 	})
 
+	t.Run("JSONRecord", func(t *testing.T) {
+		j := `{"id":"mock-id","name":"mock-name"}`
+
+		t.Run("should pass record index to callback", func(t *testing.T) {
+			c := CustomRecord("id", func(record int, val any) (any, error) {
+				test.Equal(t, "mock-id", val.(string))
+				return fmt.Sprintf("<id %d>", record), nil
+			})
+
+			res, errs := c.JSONRecord([]byte(j), 3)
+
+			test.Equal(t, 0, len(errs))
+			test.Equal(t, `{"id":"<id 3>","name":"mock-name"}`, string(res))
+		})
+
+		t.Run("should default record index to 0 outside of records", func(t *testing.T) {
+			c := CustomRecord("id", func(record int, val any) (any, error) {
+				return record, nil
+			})
+
+			res, errs := c.JSON([]byte(j))
+
+			test.Equal(t, 0, len(errs))
+			test.Equal(t, `{"id":0,"name":"mock-name"}`, string(res))
+		})
+
+		t.Run("should fallback to callback for Custom matcher", func(t *testing.T) {
+			c := Custom("id", func(val any) (any, error) {
+				return "<id>", nil
+			})
+
+			res, errs := c.JSONRecord([]byte(j), 3)
+
+			test.Equal(t, 0, len(errs))
+			test.Equal(t, `{"id":"<id>","name":"mock-name"}`, string(res))
+		})
+	})
+
 	t.Run("YAML", func(t *testing.T) {
 		y := []byte(`
 user:
@@ -3,6 +3,7 @@ package match
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/gkampitakis/go-snaps/match/internal/yaml"
@@ -51,6 +52,11 @@ func (t *typeMatcher[T]) ErrOnMissingPath(e bool) *typeMatcher[T] {
 	return t
 }
 
+// String returns the matcher as written in code e.g. match.Type[string]("user.name")
+func (t typeMatcher[ExpectedType]) String() string {
+	return "match." + t.name + "[" + reflect.TypeFor[ExpectedType]().String() + "](" + quotePaths(t.paths) + ")"
+}
+
 // YAML is intended to be called internally on snaps.MatchJSON for applying Type matchers
 func (t typeMatcher[ExpectedType]) YAML(b []byte) ([]byte, []MatcherError) {
 	var errs []MatcherError
@@ -21,6 +21,12 @@ type JSONMatcher interface {
 	JSON([]byte) ([]byte, []MatcherError)
 }
 
+// JSONRecordMatcher can optionally be implemented by a JSONMatcher that needs to know
+// the number of the record it is applied on e.g. on snaps.MatchJSONLines, where records are numbered from 1
+type JSONRecordMatcher interface {
+	JSONRecord(b []byte, record int) ([]byte, []MatcherError)
+}
+
 type YAMLMatcher interface {
 	YAML([]byte) ([]byte, []MatcherError)
 }
@@ -68,3 +74,13 @@ func expandArrayPaths(jsonInput []byte, path string) []string {
 
 	return paths
 }
+
+// quotePaths returns paths quoted and comma separated, as passed to a matcher.
+func quotePaths(paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = strconv.Quote(p)
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -443,3 +443,9 @@ func TestGjsonExpandPath(t *testing.T) {
 		}
 	})
 }
+
+func TestMatcherString(t *testing.T) {
+	test.Equal(t, `match.Any("user.name", "user.email")`, Any("user.name", "user.email").String())
+	test.Equal(t, `match.Type[map[string]interface {}]("user")`, Type[map[string]any]("user").String())
+	test.Equal(t, `match.Custom("user.age")`, Custom("user.age", nil).String())
+}
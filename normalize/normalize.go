@@ -0,0 +1,177 @@
+package normalize
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	rfc3339Pattern  = `\d{4}-\d{2}-\d{2}[Tt]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})`
+	durationPattern = `\b(\d+(\.\d+)?(ns|us|µs|ms|h|m|s))+\b`
+	hexPattern      = `\b0x[0-9a-fA-F]+\b`
+)
+
+// Normalizer transforms the serialized snapshot before it is compared and stored.
+//
+// Normalizers are intended to be passed on snaps.Normalize for replacing volatile
+// values e.g. temp paths or timestamps in unstructured snapshots.
+type Normalizer interface {
+	Normalize(s string) string
+}
+
+type regexNormalizer struct {
+	re          *regexp.Regexp
+	placeholder string
+	literal     bool
+}
+
+// Placeholder allows to define the placeholder value the matched text is replaced with
+func (r *regexNormalizer) Placeholder(p string) *regexNormalizer {
+	r.placeholder = p
+	return r
+}
+
+// Normalize is intended to be called internally on snaps for applying the normalizer
+func (r *regexNormalizer) Normalize(s string) string {
+	if r.literal {
+		return r.re.ReplaceAllLiteralString(s, r.placeholder)
+	}
+
+	return r.re.ReplaceAllString(s, r.placeholder)
+}
+
+/*
+Regex replaces every match of the pattern with the replacement.
+Inside replacement, $ signs are interpreted as in regexp.Expand, so for instance $1 represents the first submatch.
+
+	normalize.Regex(`request-id: \w+`, "request-id: <ID>")
+
+It panics if the pattern can't be parsed.
+*/
+func Regex(pattern, replacement string) *regexNormalizer {
+	return &regexNormalizer{
+		re:          regexp.MustCompile(pattern),
+		placeholder: replacement,
+	}
+}
+
+/*
+Path replaces every occurrence of the given path with a placeholder
+
+	dir := t.TempDir()
+	normalize.Path(dir).Placeholder("<DIR>")
+
+Default placeholder: "<PATH>"
+*/
+func Path(path string) *regexNormalizer {
+	return &regexNormalizer{
+		re:          regexp.MustCompile(regexp.QuoteMeta(filepath.Clean(path))),
+		placeholder: "<PATH>",
+		literal:     true,
+	}
+}
+
+/*
+TempDir replaces directories created by t.TempDir() with a placeholder
+e.g. /tmp/TestExample1234/001 becomes <TEMP_DIR>
+
+Default placeholder: "<TEMP_DIR>"
+*/
+func TempDir() *regexNormalizer {
+	sep := regexp.QuoteMeta(string(filepath.Separator))
+
+	return &regexNormalizer{
+		re: regexp.MustCompile(
+			regexp.QuoteMeta(filepath.Clean(os.TempDir())) + sep + `[^` + sep + `\s]*?\d+` + sep + `\d{3,}`,
+		),
+		placeholder: "<TEMP_DIR>",
+		literal:     true,
+	}
+}
+
+/*
+WorkingDir replaces the current working directory, as returned from os.Getwd, with a placeholder
+
+Default placeholder: "<WORKING_DIR>"
+*/
+func WorkingDir() *regexNormalizer {
+	wd, err := os.Getwd()
+	if err != nil {
+		// matches nothing
+		return &regexNormalizer{re: regexp.MustCompile(`[^\s\S]`), literal: true}
+	}
+
+	n := Path(wd)
+	n.placeholder = "<WORKING_DIR>"
+
+	return n
+}
+
+/*
+RFC3339 replaces RFC3339 timestamps e.g. 2006-01-02T15:04:05Z07:00 with a placeholder
+
+Default placeholder: "<RFC3339>"
+*/
+func RFC3339() *regexNormalizer {
+	return &regexNormalizer{
+		re:          regexp.MustCompile(rfc3339Pattern),
+		placeholder: "<RFC3339>",
+		literal:     true,
+	}
+}
+
+/*
+Duration replaces durations in the form of time.Duration.String e.g. 12ms, 1.5s, 1h2m3s with a placeholder
+
+Default placeholder: "<DURATION>"
+*/
+func Duration() *regexNormalizer {
+	return &regexNormalizer{
+		re:          regexp.MustCompile(durationPattern),
+		placeholder: "<DURATION>",
+		literal:     true,
+	}
+}
+
+/*
+HexAddress replaces hexadecimal addresses e.g. 0xc000012345 with a placeholder
+
+Default placeholder: "<HEX_ADDRESS>"
+*/
+func HexAddress() *regexNormalizer {
+	return &regexNormalizer{
+		re:          regexp.MustCompile(hexPattern),
+		placeholder: "<HEX_ADDRESS>",
+		literal:     true,
+	}
+}
+
+/*
+LineEndings converts CRLF line endings to LF, so received values with
+`\r\n` and `\n` line endings are treated as equal.
+*/
+func LineEndings() Normalizer {
+	return funcNormalizer(func(s string) string {
+		return strings.ReplaceAll(s, "\r\n", "\n")
+	})
+}
+
+type funcNormalizer func(string) string
+
+// Normalize is intended to be called internally on snaps for applying the normalizer
+func (f funcNormalizer) Normalize(s string) string {
+	return f(s)
+}
+
+/*
+Func allows you to bring your own normalization logic
+
+	normalize.Func(func(s string) string {
+		return strings.ReplaceAll(s, hostname, "<HOSTNAME>")
+	})
+*/
+func Func(fn func(string) string) Normalizer {
+	return funcNormalizer(fn)
+}
@@ -0,0 +1,114 @@
+package normalize
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func TestRegex(t *testing.T) {
+	t.Run("should replace matches", func(t *testing.T) {
+		n := Regex(`id=(\w+)`, "id=<$1>")
+
+		test.Equal(t, "user id=<abc> order id=<def>", n.Normalize("user id=abc order id=def"))
+	})
+
+	t.Run("should allow overriding placeholder", func(t *testing.T) {
+		n := Regex(`id=\w+`, "id=<ID>").Placeholder("<REDACTED>")
+
+		test.Equal(t, "user <REDACTED>", n.Normalize("user id=abc"))
+	})
+
+	t.Run("should panic on invalid pattern", func(t *testing.T) {
+		defer func() {
+			test.True(t, recover() != nil)
+		}()
+
+		Regex(`(`, "")
+	})
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+
+	n := Path(dir)
+	test.Equal(
+		t,
+		"wrote "+filepath.Join("<PATH>", "file.txt"),
+		n.Normalize("wrote "+filepath.Join(dir, "file.txt")),
+	)
+
+	n = Path(dir + string(filepath.Separator)).Placeholder("$DIR")
+	test.Equal(t, "$DIR", n.Normalize(dir))
+}
+
+func TestTempDir(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+
+	n := TempDir()
+	test.Equal(
+		t,
+		"<TEMP_DIR> and "+filepath.Join("<TEMP_DIR>", "file.txt"),
+		n.Normalize(dir+" and "+filepath.Join(other, "file.txt")),
+	)
+	test.Equal(t, "/some/other/path", n.Normalize("/some/other/path"))
+}
+
+func TestWorkingDir(t *testing.T) {
+	wd, _ := os.Getwd()
+
+	n := WorkingDir()
+	test.Equal(t, filepath.Join("<WORKING_DIR>", "main.go"), n.Normalize(filepath.Join(wd, "main.go")))
+	test.Equal(t, "cwd", WorkingDir().Placeholder("cwd").Normalize(wd))
+}
+
+func TestRFC3339(t *testing.T) {
+	n := RFC3339()
+
+	for _, tc := range []string{
+		"2024-01-02T03:04:05Z",
+		"2024-01-02T03:04:05.123456789Z",
+		"2024-01-02T03:04:05+02:00",
+		"2024-01-02t03:04:05-07:00",
+	} {
+		t.Run(tc, func(t *testing.T) {
+			test.Equal(t, "created at <RFC3339>.", n.Normalize("created at "+tc+"."))
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	n := Duration()
+
+	for _, tc := range []string{"12ms", "1.5s", "1h2m3.5s", "300µs", "40ns", "10m"} {
+		t.Run(tc, func(t *testing.T) {
+			test.Equal(t, "took <DURATION>", n.Normalize("took "+tc))
+		})
+	}
+
+	test.Equal(t, "size 10mb, 5 items", n.Normalize("size 10mb, 5 items"))
+}
+
+func TestHexAddress(t *testing.T) {
+	n := HexAddress()
+
+	test.Equal(
+		t,
+		"func at <HEX_ADDRESS>, ptr <HEX_ADDRESS>",
+		n.Normalize("func at 0xc000012345, ptr 0x1F"),
+	)
+}
+
+func TestLineEndings(t *testing.T) {
+	test.Equal(t, "line 1\nline 2\n", LineEndings().Normalize("line 1\r\nline 2\n"))
+}
+
+func TestFunc(t *testing.T) {
+	n := Func(strings.ToUpper)
+
+	test.Equal(t, "HELLO", n.Normalize("hello"))
+}
@@ -0,0 +1,152 @@
+package snaps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+When SNAPS_ARTIFACT_DIR or -snaps.artifact-dir is set, every failing comparison writes the received
+snapshot to the artifact directory, as a pending snapshot file in the same layout as the module
+
+	<artifact dir>/pkg/__snapshots__/user_test.snap.new
+	<artifact dir>/pkg/__snapshots__/TestUser_1.snap.new
+	<artifact dir>/pkg/user_test.go.snap.new
+
+so the artifact of a CI run can be applied locally with snaps.ApplyArtifacts or `go-snaps apply`
+instead of rerunning the tests. Relative artifact directories are relative to the module root.
+*/
+var artifactDir = os.Getenv("SNAPS_ARTIFACT_DIR")
+
+// handleArtifact reports if writing the received snapshot to the artifact directory failed,
+// without failing the test again.
+func handleArtifact(t testingT, err error) {
+	t.Helper()
+	if err != nil {
+		t.Log(fmt.Sprintf("go-snaps: writing snapshot artifact: %s", err))
+	}
+}
+
+// addArtifactSnapshot writes the received snapshot to the pending file of the snapshot file in the artifact directory.
+func addArtifactSnapshot(testID, snapshot, snapPath string, meta *snapshotMetadata) error {
+	if artifactDir == "" {
+		return nil
+	}
+
+	pendingPath, err := artifactPath(snapPath)
+	if err != nil {
+		return err
+	}
+
+	return writePending(pendingPath+pendingExt, testID, snapshot, pendingMetadata(pendingShared, meta))
+}
+
+// addArtifactStandaloneSnapshot writes the received snapshot to the pending file of the standalone snapshot
+// in the artifact directory.
+func addArtifactStandaloneSnapshot(testName, snapshot, snapPath string) error {
+	if artifactDir == "" {
+		return nil
+	}
+
+	pendingPath, err := artifactPath(snapPath)
+	if err != nil {
+		return err
+	}
+
+	return writePending(pendingPath+pendingExt, standalonePendingID(testName), snapshot, pendingMetadata(pendingStandalone, nil))
+}
+
+// addArtifactInlineSnapshot writes the received snapshot to the pending file of the test file in the artifact directory.
+func addArtifactInlineSnapshot(testName, filename string, line int, snapshot string) error {
+	if artifactDir == "" {
+		return nil
+	}
+
+	idx := inlineSnapshotLineMapping.GetIndex(filename, line)
+	if idx == -1 {
+		return errLocateCall
+	}
+	pendingPath, err := artifactPath(filename)
+	if err != nil {
+		return err
+	}
+
+	return writePending(
+		pendingPath+snapsExt+pendingExt,
+		inlinePendingID(testName, idx),
+		snapshot,
+		pendingMetadata(pendingInline, nil),
+	)
+}
+
+// artifactPath returns where the artifact of path is written, keeping its path relative to the module root.
+func artifactPath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	root := moduleRoot(filepath.Dir(path))
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := artifactDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+
+	return filepath.Join(dir, rel), nil
+}
+
+// moduleRoot returns the closest parent directory of dir with a go.mod file, or dir if there is none.
+func moduleRoot(dir string) string {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+/*
+ApplyArtifacts applies the received snapshots written to the artifact directory, when running tests with
+SNAPS_ARTIFACT_DIR, to the snapshots of the module at root, replacing the failing snapshots.
+
+	snaps.ApplyArtifacts("./ci-artifacts/snapshots", ".")
+
+Only the snapshots inside the artifacts are changed, the artifacts are left in place.
+*/
+func ApplyArtifacts(dir, root string) error {
+	files, err := PendingFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		snapshots, err := readPending(file, filepath.Join(root, strings.TrimSuffix(rel, pendingExt)))
+		if err != nil {
+			return err
+		}
+		for _, p := range snapshots {
+			if err := p.apply(); err != nil {
+				return fmt.Errorf("%s: [%s]: %w", p.Target, p.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package snaps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+const artifactFilename = "artifact_test.snap"
+
+func setupArtifacts(t *testing.T) string {
+	t.Helper()
+	artifactDir = t.TempDir()
+	t.Cleanup(func() { artifactDir = "" })
+
+	return artifactDir
+}
+
+func TestArtifacts(t *testing.T) {
+	t.Run("should write the received snapshot of a failing comparison", func(t *testing.T) {
+		snapPath := setupSnapshot(t, artifactFilename, true)
+		dir := setupArtifacts(t)
+		test.NoError(t, os.WriteFile(snapPath, []byte("\n[mock-name - 1]\nhello world\n---\n"), 0o644))
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) { test.Contains(t, args[0].(string), "hello") }
+		MatchSnapshot(mockT, "bye world")
+
+		test.Equal(t, "\n[mock-name - 1]\nhello world\n---\n", test.GetFileContent(t, snapPath))
+		test.Equal(
+			t,
+			"# format: 2\n# pending: shared\n\n[mock-name - 1]\nbye world\n---\n",
+			test.GetFileContent(t, filepath.Join(dir, "snaps", "__snapshots__", artifactFilename+pendingExt)),
+		)
+
+		test.NoError(t, ApplyArtifacts(dir, moduleRoot(filepath.Dir(snapPath))))
+
+		test.Equal(t, "\n[mock-name - 1]\nbye world\n---\n", test.GetFileContent(t, snapPath))
+	})
+
+	t.Run("should write the received snapshot of a missing standalone snapshot", func(t *testing.T) {
+		name := "TestArtifacts_should_write_the_received_snapshot_of_a_missing_standalone_snapshot_1.snap"
+		snapPath := setupSnapshot(t, name, true)
+		dir := setupArtifacts(t)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockName = func() string { return t.Name() }
+		mockT.MockError = func(args ...any) { test.Equal(t, errSnapNotFound, args[0].(error)) }
+		MatchStandaloneSnapshot(mockT, "hello world")
+
+		_, err := os.Stat(snapPath)
+		test.True(t, os.IsNotExist(err))
+		test.Equal(
+			t,
+			"# format: 2\n# pending: standalone\n\n["+t.Name()+" - standalone]\nhello world\n---\n",
+			test.GetFileContent(t, filepath.Join(dir, "snaps", "__snapshots__", name+pendingExt)),
+		)
+	})
+
+	t.Run("should not write artifacts of passing comparisons", func(t *testing.T) {
+		snapPath := setupSnapshot(t, artifactFilename, true)
+		dir := setupArtifacts(t)
+		test.NoError(t, os.WriteFile(snapPath, []byte("\n[mock-name - 1]\nhello world\n---\n"), 0o644))
+
+		MatchSnapshot(test.NewMockTestingT(t), "hello world")
+
+		files, err := PendingFiles(dir)
+		test.NoError(t, err)
+		test.Equal(t, 0, len(files))
+	})
+
+	t.Run("should keep artifact paths relative to the module root", func(t *testing.T) {
+		artifactDir = "snapshot-artifacts"
+		t.Cleanup(func() { artifactDir = "" })
+		dir, _ := os.Getwd()
+		root := moduleRoot(dir)
+
+		path, err := artifactPath(filepath.Join("__snapshots__", "a_test.snap"))
+
+		test.NoError(t, err)
+		test.Equal(t, filepath.Join(root, "snapshot-artifacts", "snaps", "__snapshots__", "a_test.snap"), path)
+		_, err = os.Stat(filepath.Join(root, "go.mod"))
+		test.NoError(t, err)
+	})
+}
@@ -0,0 +1,179 @@
+package snaps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// blobsDir is the directory, inside the snapshot directory, where externalized snapshots are stored
+	blobsDir = "blobs"
+	blobKey  = "blob"
+)
+
+var errInvalidBlob = errors.New("invalid snapshot blob reference")
+
+// externalize moves a snapshot larger than the configured threshold to a content-addressed blob
+// file, returning the snapshot and metadata to store in the snapshot file instead.
+//
+// Blobs are written right away even if writes are deferred, as they are never modified.
+func (c *Config) externalize(
+	snapPath, snapshot string,
+	meta *snapshotMetadata,
+) (string, *snapshotMetadata, error) {
+	if c.blobThreshold <= 0 || len(snapshot) <= c.blobThreshold {
+		return snapshot, meta, nil
+	}
+
+	sum := sha256.Sum256([]byte(snapshot))
+	ref := path.Join(blobsDir, hex.EncodeToString(sum[:])+snapsExt)
+	storage := storageFor(snapPath)
+	blobPath := blobFilePath(snapPath, ref)
+
+	_, err := storage.Read(blobPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = storage.Write(blobPath, []byte(snapshot))
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "", meta.with(metadataField{key: blobKey, value: ref}), nil
+}
+
+// blob returns the reference of the blob the snapshot under testID is stored in, if any.
+func (f *snapshotFile) blob(testID string) string {
+	f.RLock()
+	defer f.RUnlock()
+
+	e, ok := f.entries[testID]
+	if !ok {
+		return ""
+	}
+
+	return f.metadata(e).get(blobKey)
+}
+
+// readBlob returns the snapshot stored in the blob referenced from the snapshot file.
+func readBlob(snapPath, ref string) (string, error) {
+	if !isBlobRef(ref) {
+		return "", fmt.Errorf("%w: %q", errInvalidBlob, ref)
+	}
+
+	b, err := storageFor(snapPath).Read(blobFilePath(snapPath, ref))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func blobFilePath(snapPath, ref string) string {
+	return filepath.Join(filepath.Dir(snapPath), filepath.FromSlash(ref))
+}
+
+// isBlobRef reports whether ref is in the form blobs/<sha256>.snap
+func isBlobRef(ref string) bool {
+	name, ok := strings.CutPrefix(ref, blobsDir+"/")
+	if !ok {
+		return false
+	}
+	sum, ok := strings.CutSuffix(name, snapsExt)
+	if !ok || len(sum) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(sum)
+
+	return err == nil
+}
+
+// examineBlobs checks the blobs directory of every snapshot directory for blobs no longer referenced
+// by any snapshot file.
+//
+// If they are not referenced they are marked as obsolete and they are either deleted if `shouldUpdate=true`
+// or printed on the console.
+func examineBlobs(
+	registry map[string]map[string]int,
+	obsoleteFiles, obsoleteTests []string,
+	shouldUpdate bool,
+) (obsolete []string, dirtyBlobs bool) {
+	uniqueDirs := set{}
+	for snapPath := range registry {
+		uniqueDirs[filepath.Dir(snapPath)] = struct{}{}
+	}
+
+	for dir := range uniqueDirs {
+		for _, storage := range storages.getDir(dir) {
+			blobs, err := storage.List(filepath.Join(dir, blobsDir))
+			if err != nil || len(blobs) == 0 {
+				continue
+			}
+
+			referenced := set{}
+			// blobs referenced by files of unsupported formats are unknown, so none are removed
+			unsupported := false
+			dirContents, _ := storage.List(dir)
+			for _, filename := range dirContents {
+				snapPath := filepath.Join(dir, filename)
+				if !strings.Contains(filename, snapsExt) || slices.Contains(obsoleteFiles, snapPath) {
+					continue
+				}
+
+				data, err := storage.Read(snapPath)
+				if err != nil {
+					continue
+				}
+
+				f, err := parseSnapshotFile(data)
+				if err != nil {
+					unsupported = true
+					break
+				}
+				for _, e := range f.order {
+					// without updating, obsolete snapshots are still in the files
+					testID, _ := getTestID([]byte(e.id))
+					if !shouldUpdate && slices.Contains(obsoleteTests, testID) {
+						continue
+					}
+					if ref := f.metadata(e).get(blobKey); ref != "" {
+						referenced[path.Base(ref)] = struct{}{}
+					}
+				}
+			}
+
+			if unsupported {
+				continue
+			}
+
+			for _, blob := range blobs {
+				if referenced.Has(blob) {
+					continue
+				}
+
+				// storages of the same directory can share files e.g. snaps.NewFSStorage over the package directory
+				blobPath := filepath.Join(dir, blobsDir, blob)
+				if slices.Contains(obsolete, blobPath) {
+					continue
+				}
+				obsolete = append(obsolete, blobPath)
+
+				if !shouldUpdate {
+					continue
+				}
+
+				if err := storage.Delete(blobPath); err != nil {
+					fmt.Println(err)
+				}
+			}
+		}
+	}
+
+	return obsolete, len(obsolete) > 0
+}
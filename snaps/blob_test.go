@@ -0,0 +1,170 @@
+package snaps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+const blobFilename = "blob_test.snap"
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestBlobThreshold(t *testing.T) {
+	large := strings.Repeat("line\n", 10) + "---"
+
+	t.Run("should store large snapshots in blobs", func(t *testing.T) {
+		snapPath := setupSnapshot(t, blobFilename, false)
+		c := WithConfig(BlobThreshold(20), Filename("blob_test"))
+		ref := filepath.ToSlash(filepath.Join(blobsDir, sha256Hex(large)+snapsExt))
+		t.Cleanup(func() { os.RemoveAll(filepath.Join(filepath.Dir(snapPath), blobsDir)) })
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+
+		c.MatchSnapshot(mockT, large)
+		mockT.MockName = func() string { return "mock-name-small" }
+		c.MatchSnapshot(mockT, "small")
+
+		test.Equal(
+			t,
+			"# format: 2\n# go-snaps: "+snapsVersion()+"\n"+
+				"\n[mock-name - 1]\n#@ blob: "+ref+"\n\n---\n"+
+				"\n[mock-name-small - 1]\nsmall\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+		test.Equal(t, large, test.GetFileContent(t, blobFilePath(snapPath, ref)))
+
+		snap, line, err := getPrevSnapshot("[mock-name - 1]", snapPath)
+		test.NoError(t, err)
+		test.Equal(t, large, snap)
+		test.Equal(t, 4, line)
+	})
+
+	t.Run("should diff against blob", func(t *testing.T) {
+		setupSnapshot(t, blobFilename, false)
+		c := WithConfig(BlobThreshold(20), Filename("blob_test"))
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+		c.MatchSnapshot(mockT, large)
+
+		mockT.MockError = func(args ...any) {
+			test.Contains(t, args[0].(string), "line")
+			test.Contains(t, args[0].(string), "changed")
+		}
+		c.MatchSnapshot(mockT, large+"\nchanged")
+	})
+
+	t.Run("should move snapshot back inline when updated below threshold", func(t *testing.T) {
+		snapPath := setupSnapshot(t, blobFilename, false, "true")
+		c := WithConfig(BlobThreshold(20), Filename("blob_test"))
+		t.Cleanup(func() { os.RemoveAll(filepath.Join(filepath.Dir(snapPath), blobsDir)) })
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+		c.MatchSnapshot(mockT, large)
+		c.MatchSnapshot(mockT, "small")
+
+		test.Equal(
+			t,
+			"# format: 2\n# go-snaps: "+snapsVersion()+"\n\n[mock-name - 1]\nsmall\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
+	t.Run("should return error for invalid blob reference", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__", "blob.snap")
+		test.NoError(t, os.MkdirAll(filepath.Dir(snapPath), 0o755))
+		test.NoError(
+			t,
+			os.WriteFile(snapPath, []byte("# format: 2\n\n[TestA - 1]\n#@ blob: ../../secret\n\n---\n"), 0o644),
+		)
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		_, _, err := getPrevSnapshot("[TestA - 1]", snapPath)
+		test.True(t, errors.Is(err, errInvalidBlob))
+	})
+}
+
+func TestExamineBlobs(t *testing.T) {
+	setup := func(t *testing.T) (string, string, string) {
+		t.Helper()
+		dir := filepath.Join(t.TempDir(), "__snapshots__")
+		used, unused := sha256Hex("used"), sha256Hex("unused")
+		test.NoError(t, os.MkdirAll(filepath.Join(dir, blobsDir), 0o755))
+		test.NoError(t, os.WriteFile(filepath.Join(dir, blobsDir, used+snapsExt), []byte("used"), 0o644))
+		test.NoError(t, os.WriteFile(filepath.Join(dir, blobsDir, unused+snapsExt), []byte("unused"), 0o644))
+		test.NoError(t, os.WriteFile(
+			filepath.Join(dir, "test.snap"),
+			[]byte("# format: 2\n\n[TestA - 1]\n#@ blob: blobs/"+used+".snap\n\n---\n\n"+
+				"[TestB - 1]\n#@ blob: blobs/"+unused+".snap\n\n---\n"),
+			0o644,
+		))
+
+		return dir, used, unused
+	}
+
+	t.Run("should keep referenced blobs", func(t *testing.T) {
+		dir, _, _ := setup(t)
+		registry := map[string]map[string]int{filepath.Join(dir, "test.snap"): {}}
+
+		obsolete, dirty := examineBlobs(registry, nil, nil, true)
+
+		test.Equal(t, 0, len(obsolete))
+		test.False(t, dirty)
+	})
+
+	t.Run("should report blobs of obsolete snapshots", func(t *testing.T) {
+		dir, _, unused := setup(t)
+		registry := map[string]map[string]int{filepath.Join(dir, "test.snap"): {}}
+		unusedPath := filepath.Join(dir, blobsDir, unused+snapsExt)
+
+		obsolete, dirty := examineBlobs(registry, nil, []string{"TestB - 1"}, false)
+
+		test.Equal(t, []string{unusedPath}, obsolete)
+		test.True(t, dirty)
+		// not removed
+		_, err := os.Stat(unusedPath)
+		test.NoError(t, err)
+	})
+
+	t.Run("should remove unreferenced blobs", func(t *testing.T) {
+		dir, used, unused := setup(t)
+		snapPath := filepath.Join(dir, "test.snap")
+		registry := map[string]map[string]int{snapPath: {}}
+		test.NoError(t, os.WriteFile(
+			snapPath,
+			[]byte("# format: 2\n\n[TestA - 1]\n#@ blob: blobs/"+used+".snap\n\n---\n"),
+			0o644,
+		))
+
+		obsolete, dirty := examineBlobs(registry, nil, nil, true)
+
+		test.Equal(t, []string{filepath.Join(dir, blobsDir, unused+snapsExt)}, obsolete)
+		test.True(t, dirty)
+		_, err := os.Stat(filepath.Join(dir, blobsDir, unused+snapsExt))
+		test.True(t, errors.Is(err, os.ErrNotExist))
+		_, err = os.Stat(filepath.Join(dir, blobsDir, used+snapsExt))
+		test.NoError(t, err)
+	})
+
+	t.Run("should treat blobs of obsolete files as unreferenced", func(t *testing.T) {
+		dir, _, _ := setup(t)
+		snapPath := filepath.Join(dir, "test.snap")
+		registry := map[string]map[string]int{snapPath: {}}
+
+		obsolete, _ := examineBlobs(registry, []string{snapPath}, nil, false)
+
+		test.Equal(t, 2, len(obsolete))
+	})
+}
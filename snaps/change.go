@@ -0,0 +1,128 @@
+package snaps
+
+import "fmt"
+
+// snapshotChange is a missing or mismatching snapshot, along with how each kind of snapshot
+// is stored, so every matcher follows the same steps for creating or updating it.
+type snapshotChange struct {
+	// file is the snapshot file, or the test file for inline snapshots
+	file string
+	// id identifies the snapshot in the dry-run plan
+	id string
+	// line is where the snapshot is in file, for the dry-run plan
+	line     int
+	snapshot string
+	// prev is the stored snapshot, nil when the snapshot is missing
+	prev *string
+	// diff is the failure message of a mismatching snapshot
+	diff string
+
+	frozen   func() bool
+	pend     func() error
+	artifact func() error
+	write    func(created bool) error
+}
+
+// sharedChange returns the change of a snapshot stored in a snapshot file shared by the tests of a file.
+func (c *Config) sharedChange(testID, snapPath, snapshot string, meta *snapshotMetadata) snapshotChange {
+	return snapshotChange{
+		file:     snapPath,
+		id:       testID,
+		snapshot: snapshot,
+		frozen:   func() bool { return c.isFrozenSnapshot(testID, snapPath) },
+		pend:     func() error { return c.addPendingSnapshot(testID, snapshot, snapPath, meta) },
+		artifact: func() error { return addArtifactSnapshot(testID, snapshot, snapPath, meta) },
+		write: func(created bool) error {
+			snapshot, meta, err := c.externalize(snapPath, snapshot, meta)
+			if err != nil {
+				return err
+			}
+			if created {
+				return addNewSnapshot(testID, snapshot, snapPath, meta, c.deferred)
+			}
+
+			return updateSnapshot(testID, snapshot, snapPath, meta, c.deferred)
+		},
+	}
+}
+
+// standaloneChange returns the change of a snapshot stored in its own snapshot file.
+func (c *Config) standaloneChange(testName, snapPath, snapshot string) snapshotChange {
+	return snapshotChange{
+		file:     snapPath,
+		id:       testName,
+		snapshot: snapshot,
+		frozen:   func() bool { return c.frozen },
+		pend:     func() error { return addPendingStandaloneSnapshot(testName, snapshot, snapPath) },
+		artifact: func() error { return addArtifactStandaloneSnapshot(testName, snapshot, snapPath) },
+		write:    func(bool) error { return upsertStandaloneSnapshot(snapshot, snapPath) },
+	}
+}
+
+// inlineChange returns the change of a snapshot stored in the test file, at the call in line.
+func (c *Config) inlineChange(testName, filename string, line int, snapshot string) snapshotChange {
+	return snapshotChange{
+		file:     filename,
+		id:       testName,
+		line:     line,
+		snapshot: snapshot,
+		frozen:   func() bool { return c.frozen },
+		pend:     func() error { return addPendingInlineSnapshot(testName, filename, line, snapshot) },
+		artifact: func() error { return addArtifactInlineSnapshot(testName, filename, line, snapshot) },
+		write:    func(bool) error { return upsertInlineSnapshot(filename, line, snapshot) },
+	}
+}
+
+// handleChange creates the missing snapshot, or updates the mismatching one, if the update policy allows it.
+//
+// Frozen snapshots are never updated, with UPDATE_SNAPS=pending the snapshot is written for review,
+// when not allowed the test fails and the snapshot is written to the artifact directory
+// and in dry-run mode the change is only planned.
+func (c *Config) handleChange(t testingT, ch snapshotChange) {
+	t.Helper()
+
+	// an invalid UPDATE_SNAPS would otherwise silently match no tests
+	if err := checkUpdate(updateVAR); err != nil {
+		handleError(t, fmt.Errorf("UPDATE_SNAPS: %w", err))
+		return
+	}
+
+	created := ch.prev == nil
+	if !created && ch.frozen() {
+		handleError(t, ch.diff+frozenMsg)
+		return
+	}
+	if shouldPend(c.update) {
+		handlePending(t, ch.diff, ch.pend())
+		return
+	}
+
+	if created && !shouldCreate(c.update, t.Name()) {
+		handleError(t, errSnapNotFound)
+		handleArtifact(t, ch.artifact())
+		return
+	}
+	if !created && !shouldUpdate(c.update, t.Name()) {
+		handleError(t, ch.diff)
+		handleArtifact(t, ch.artifact())
+		return
+	}
+
+	if planSnapshot(t, ch.file, ch.id, ch.line, ch.prev, ch.snapshot) {
+		return
+	}
+
+	if err := ch.write(created); err != nil {
+		handleError(t, err)
+		return
+	}
+
+	if created {
+		t.Log(addedMsg)
+		testEvents.register(added)
+		return
+	}
+
+	t.Log(updatedMsg)
+	testEvents.register(updated)
+}
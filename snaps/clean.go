@@ -5,13 +5,14 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"os"
+	"maps"
 	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"unicode"
 
 	"github.com/gkampitakis/go-snaps/internal/colors"
 	"github.com/maruel/natural"
@@ -24,6 +25,7 @@ const (
 	added
 	updated
 	passed
+	pending
 )
 
 type events struct {
@@ -95,64 +97,114 @@ func Clean(m *testing.M, opts ...CleanOpts) (bool, error) {
 	}
 	// This is just for making sure Clean is called from TestMain
 	_ = m
-	runOnly := flag.Lookup("test.run").Value.String()
+	if err := checkUpdate(updateVAR); err != nil {
+		return false, fmt.Errorf("UPDATE_SNAPS: %w", err)
+	}
+	if err := Flush(); err != nil {
+		return false, err
+	}
+	filter := newTestFilter(
+		flag.Lookup("test.run").Value.String(),
+		flag.Lookup("test.skip").Value.String(),
+	)
 	count, _ := strconv.Atoi(flag.Lookup("test.count").Value.String())
 	registeredStandaloneTests := occurrences(
 		standaloneTestsRegistry.cleanup,
 		count,
 		standaloneOccurrenceFMT,
 	)
+	maps.Copy(registeredStandaloneTests, standaloneTestsRegistry.cleanupNames)
+
+	clean := shouldClean()
+	// in dry-run mode nothing is removed, only added to the plan,
+	// whether the active policy would remove it or not
+	var cleanPlan *dryRunPlan
+	if isDryRun() {
+		plan.Clean = clean
+		cleanPlan = plan
+		clean = false
+	}
 
 	obsoleteFiles, usedFiles, filesDirty := examineFiles(
 		testsRegistry.cleanup,
 		registeredStandaloneTests,
-		runOnly,
-		shouldClean,
+		filter,
+		clean,
 	)
 	obsoleteTests, snapsDirty, err := examineSnaps(
 		testsRegistry.cleanup,
+		testsRegistry.cleanupNames,
 		usedFiles,
-		runOnly,
+		filter,
 		count,
-		shouldClean,
+		clean,
 		opt.Sort,
+		cleanPlan,
 	)
 	if err != nil {
 		return snapsDirty || filesDirty, err
 	}
+	obsoleteBlobs, blobsDirty := examineBlobs(
+		testsRegistry.cleanup,
+		obsoleteFiles,
+		obsoleteTests,
+		clean,
+	)
+	obsoleteFiles = append(obsoleteFiles, obsoleteBlobs...)
 
 	if s := summary(
 		obsoleteFiles,
 		obsoleteTests,
 		len(skippedTests.values),
 		testEvents.items,
-		shouldClean,
+		clean,
+		settings(),
 	); s != "" {
 		fmt.Println(s)
 	}
 
-	return filesDirty || snapsDirty, nil
+	if isDryRun() {
+		plan.Deleted = append(plan.Deleted, relPaths(obsoleteFiles)...)
+		plan.Settings = settings()
+		if err := plan.write(); err != nil {
+			return true, err
+		}
+
+		return filesDirty || snapsDirty || blobsDirty || !plan.empty(), nil
+	}
+
+	return filesDirty || snapsDirty || blobsDirty, nil
 }
 
 // getTestID will return the testID if the line is in the form of [Test... - number]
+// or [Test... - name] for named snapshots
 func getTestID(b []byte) (string, bool) {
-	if len(b) == 0 {
+	id, ok := parseTestID(b)
+	if !ok || !strings.HasPrefix(id, "Test") {
 		return "", false
 	}
 
-	// needs to start with [Test and end with ]
-	if !bytes.HasPrefix(b, []byte("[Test")) || b[len(b)-1] != ']' {
+	return id, true
+}
+
+// parseTestID returns the snapshot id if the line is in the form of [<test name> - number]
+// or [<test name> - name]. Test names never contain spaces, go test replaces them with underscores.
+func parseTestID(b []byte) (string, bool) {
+	b = bytes.TrimSuffix(b, []byte{'\r'})
+
+	// needs to start with [ and end with ]
+	if len(b) < 2 || b[0] != '[' || b[len(b)-1] != ']' {
 		return "", false
 	}
 
-	// needs to contain ' - '
+	// needs to contain ' - ' after the test name
 	separator := bytes.Index(b, []byte(" - "))
-	if separator == -1 {
+	if separator <= 1 || bytes.ContainsFunc(b[1:separator], unicode.IsSpace) {
 		return "", false
 	}
 
-	// needs to have a number after the separator
-	if !isNumber(b[separator+3 : len(b)-1]) {
+	// needs to have a number or a valid name after the separator
+	if id := b[separator+3 : len(b)-1]; len(id) == 0 || !isNumber(id) && !isValidName(string(id)) {
 		return "", false
 	}
 
@@ -169,14 +221,14 @@ func isNumber(b []byte) bool {
 	return true
 }
 
-// examineFiles traverses all the directories where snap tests where executed and checks
-// if "orphan" snap files exist (files containing `.snap` in their name).
+// examineFiles traverses all the directories where snap tests where executed, in every storage
+// used for them, and checks if "orphan" snap files exist (files containing `.snap` in their name).
 //
 // If they do they are marked as obsolete and they are either deleted if `shouldUpdate=true` or printed on the console.
 func examineFiles(
 	registry map[string]map[string]int,
 	registeredStandaloneTests set,
-	runOnly string,
+	filter testFilter,
 	shouldUpdate bool,
 ) (obsolete, used []string, dirtyFiles bool) {
 	uniqueDirs := set{}
@@ -189,110 +241,153 @@ func examineFiles(
 		uniqueDirs[filepath.Dir(snapPaths)] = struct{}{}
 	}
 
+	// files found in more than one storage of the same directory are examined once
+	examined := set{}
 	for dir := range uniqueDirs {
-		dirContents, _ := os.ReadDir(dir)
+		for _, storage := range storages.getDir(dir) {
+			o, u := examineDir(dir, storage, registry, registeredStandaloneTests, examined, filter, shouldUpdate)
+			obsolete = append(obsolete, o...)
+			used = append(used, u...)
+		}
+	}
 
-		for _, content := range dirContents {
-			// this is a sanity check shouldn't have dirs inside the snapshot dirs
-			// and only delete any `.snap` files
-			if content.IsDir() || !strings.Contains(content.Name(), snapsExt) {
-				continue
-			}
+	return obsolete, used, len(obsolete) > 0
+}
 
-			snapPath := filepath.Join(dir, content.Name())
-			if _, called := registry[snapPath]; called {
-				used = append(used, snapPath)
-				continue
-			}
+// examineDir examines the snapshot files of dir inside storage, see examineFiles.
+func examineDir(
+	dir string,
+	storage Storage,
+	registry map[string]map[string]int,
+	registeredStandaloneTests, examined set,
+	filter testFilter,
+	shouldUpdate bool,
+) (obsolete, used []string) {
+	dirContents, _ := storage.List(dir)
 
-			// if it's a standalone snapshot we don't add it to used list
-			// as we don't need it for the next step, to examine individual snaps inside the file
-			// as it contains only one
-			if registeredStandaloneTests.Has(snapPath) {
-				continue
-			}
+	for _, filename := range dirContents {
+		// only delete any `.snap` files, pending snapshots are left for review
+		if !strings.Contains(filename, snapsExt) || isPendingFile(filename) {
+			continue
+		}
 
-			if isFileSkipped(dir, content.Name(), runOnly) {
-				continue
-			}
+		snapPath := filepath.Join(dir, filename)
+		if examined.Has(snapPath) {
+			continue
+		}
+		examined[snapPath] = struct{}{}
 
-			obsolete = append(obsolete, snapPath)
+		if _, called := registry[snapPath]; called {
+			used = append(used, snapPath)
+			continue
+		}
 
-			if !shouldUpdate {
-				continue
-			}
+		// if it's a standalone snapshot we don't add it to used list
+		// as we don't need it for the next step, to examine individual snaps inside the file
+		// as it contains only one
+		if registeredStandaloneTests.Has(snapPath) {
+			continue
+		}
 
-			if err := os.Remove(snapPath); err != nil {
-				fmt.Println(err)
-			}
+		if isFileSkipped(dir, filename, filter) {
+			continue
+		}
+
+		// files with frozen snapshots are never deleted, only their obsolete snapshots are removed
+		if data, err := storage.Read(snapPath); err == nil && hasFrozen(data) {
+			// the file wasn't called, so its storage is registered for examining its snapshots
+			storages.register(snapPath, storage)
+			used = append(used, snapPath)
+			continue
+		}
+
+		obsolete = append(obsolete, snapPath)
+
+		if !shouldUpdate {
+			continue
+		}
+
+		snapshotFiles.invalidate(snapPath)
+		if err := storage.Delete(snapPath); err != nil {
+			fmt.Println(err)
 		}
 	}
 
-	return obsolete, used, len(obsolete) > 0
+	return obsolete, used
 }
 
 func examineSnaps(
 	registry map[string]map[string]int,
+	namedRegistry map[string]set,
 	used []string,
-	runOnly string,
+	filter testFilter,
 	count int,
 	shouldUpdate,
 	sort bool,
+	plan *dryRunPlan,
 ) ([]string, bool, error) {
 	obsoleteTests := []string{}
-	tests := map[string]string{}
-	data := bytes.Buffer{}
-	testIDs := []string{}
 	var isDirty bool
 
 	for _, snapPath := range used {
-		f, err := os.OpenFile(snapPath, os.O_RDWR, 0o644)
+		// the snapshot file is locked while being read and rewritten
+		unlock := func() {}
+		if shouldUpdate {
+			var err error
+			if unlock, err = lockFile(snapPath); err != nil {
+				return nil, isDirty, err
+			}
+		}
+
+		storage := storageFor(snapPath)
+		data, err := storage.Read(snapPath)
 		if err != nil {
+			unlock()
 			return nil, isDirty, err
 		}
 
 		var needsUpdating bool
 
 		registeredTests := occurrences(registry[snapPath], count, snapshotOccurrenceFMT)
-		s := snapshotScanner(f)
+		maps.Copy(registeredTests, namedRegistry[snapPath])
+		f, err := parseSnapshotFile(data)
+		if err != nil {
+			unlock()
+			return nil, isDirty, fmt.Errorf("%w: %s", err, snapPath)
+		}
 
-		for s.Scan() {
-			b := s.Bytes()
-			// Check if line is a test id
-			testID, match := getTestID(b)
+		testIDs := make([]string, 0, len(f.order))
+		kept := make([]*indexEntry, 0, len(f.order))
+		for _, e := range f.order {
+			testID, match := getTestID([]byte(e.id))
 			if !match {
 				continue
 			}
 			testIDs = append(testIDs, testID)
 
-			if !registeredTests.Has(testID) && !testSkipped(testID, runOnly) {
+			// frozen snapshots are never removed
+			if !registeredTests.Has(testID) && !testSkipped(testID, filter) && !isFrozen(f.metadata(e)) {
 				obsoleteTests = append(obsoleteTests, testID)
 				needsUpdating = true
-
-				removeSnapshot(s)
-				continue
-			}
-
-			for s.Scan() {
-				line := s.Bytes()
-
-				if bytes.Equal(line, endSequenceByteSlice) {
-					tests[testID] = data.String()
-
-					data.Reset()
-					break
+				if plan != nil {
+					plan.planRemoved(snapPath, testID, e.line, f.snapshot(e))
 				}
-
-				data.Write(line)
-				data.WriteByte('\n')
+				continue
 			}
-		}
 
-		if err := s.Err(); err != nil {
-			return nil, isDirty, err
+			kept = append(kept, e)
 		}
 
 		needsSorting := sort && !slices.IsSortedFunc(testIDs, naturalSort)
+		if needsSorting && plan != nil {
+			keptIDs := make([]string, 0, len(kept))
+			for _, e := range kept {
+				keptIDs = append(keptIDs, entryID(e))
+			}
+			if !slices.IsSortedFunc(keptIDs, naturalSort) {
+				plan.planSorted(snapPath, keptIDs)
+			}
+		}
 
 		// if we're not allowed to update anything, just capture if the snapshot
 		// needs cleaning, and then continue to the next snapshot
@@ -301,37 +396,23 @@ func examineSnaps(
 				isDirty = true
 			}
 
-			f.Close()
-
-			clear(tests)
-			testIDs = testIDs[:0]
-			data.Reset()
-
 			continue
 		}
 
 		if needsSorting {
-			// sort testIDs
-			slices.SortFunc(testIDs, naturalSort)
+			slices.SortStableFunc(kept, func(a, b *indexEntry) int {
+				idA, _ := getTestID([]byte(a.id))
+				idB, _ := getTestID([]byte(b.id))
+				return naturalSort(idA, idB)
+			})
 		}
 
-		if err := overwriteFile(f, nil); err != nil {
+		snapshotFiles.invalidate(snapPath)
+		err = storage.Write(snapPath, f.render(kept))
+		unlock()
+		if err != nil {
 			return nil, isDirty, err
 		}
-
-		for _, id := range testIDs {
-			test, ok := tests[id]
-			if !ok {
-				continue
-			}
-
-			fmt.Fprintf(f, "\n[%s]\n%s%s\n", id, test, endSequence)
-		}
-		f.Close()
-
-		clear(tests)
-		testIDs = testIDs[:0]
-		data.Reset()
 	}
 
 	return obsoleteTests, isDirty, nil
@@ -341,6 +422,7 @@ func summary(
 	obsoleteFiles, obsoleteTests []string, NOskippedTests int,
 	testEvents map[uint8]int,
 	shouldUpdate bool,
+	settings string,
 ) string {
 	if len(obsoleteFiles) == 0 &&
 		len(obsoleteTests) == 0 &&
@@ -379,11 +461,13 @@ func summary(
 	}
 
 	fmt.Fprintf(&s, "\n%s\n\n", colors.Sprint(colors.BoldWhite, "Snapshot Summary"))
+	fmt.Fprintf(&s, "%s\n\n", colors.Sprint(colors.Dim, "Settings: "+settings))
 
 	printEvent(&s, colors.Green, successSymbol, "passed", testEvents[passed])
 	printEvent(&s, colors.Red, errorSymbol, "failed", testEvents[erred])
 	printEvent(&s, colors.Green, updateSymbol, "added", testEvents[added])
 	printEvent(&s, colors.Green, updateSymbol, "updated", testEvents[updated])
+	printEvent(&s, colors.Yellow, updateSymbol, "pending", testEvents[pending])
 	printEvent(&s, colors.Yellow, skipSymbol, "skipped", NOskippedTests)
 
 	if len(obsoleteFiles) > 0 {
@@ -430,8 +514,8 @@ func standaloneOccurrenceFMT(s string, i int) string {
 	return fmt.Sprintf(s, i)
 }
 
-func snapshotOccurrenceFMT(s string, i int) string {
-	return fmt.Sprintf("%s - %d", s, i)
+func snapshotOccurrenceFMT[T int | string](s string, i T) string {
+	return fmt.Sprintf("%s - %v", s, i)
 }
 
 // Builds a Set with all snapshot ids registered. It uses the provider formatter to build keys.
@@ -119,7 +119,7 @@ func TestExamineFiles(t *testing.T) {
 		obsolete, used, isDirty := examineFiles(tests, set{
 			dir1 + "TestSomething_my_test_1.snap":           struct{}{},
 			dir2 + "TestAnotherThing_my_simple_test_1.snap": struct{}{},
-		}, "", false)
+		}, testFilter{}, false)
 
 		obsoleteExpected := []string{
 			filepath.FromSlash(dir1 + "/obsolete1.snap"),
@@ -155,7 +155,7 @@ func TestExamineFiles(t *testing.T) {
 		examineFiles(tests, set{
 			dir1 + "TestSomething_my_test_1.snap":           struct{}{},
 			dir2 + "TestAnotherThing_my_simple_test_1.snap": struct{}{},
-		}, "", shouldUpdate)
+		}, testFilter{}, shouldUpdate)
 
 		for _, obsoleteFilename := range []string{
 			dir1 + "/obsolete1.snap",
@@ -188,7 +188,7 @@ func TestExamineSnaps(t *testing.T) {
 			filepath.FromSlash(dir2 + "/test2.snap"),
 		}
 
-		obsolete, isDirty, err := examineSnaps(tests, used, "", 1, shouldUpdate, sort)
+		obsolete, isDirty, err := examineSnaps(tests, nil, used, testFilter{}, 1, shouldUpdate, sort, nil)
 
 		test.Equal(t, []string{}, obsolete)
 		test.NoError(t, err)
@@ -210,7 +210,7 @@ func TestExamineSnaps(t *testing.T) {
 		// Removing the test entirely
 		delete(tests[used[1]], "TestDir2_2/TestSimple")
 
-		obsolete, isDirty, err := examineSnaps(tests, used, "", 1, shouldUpdate, sort)
+		obsolete, isDirty, err := examineSnaps(tests, nil, used, testFilter{}, 1, shouldUpdate, sort, nil)
 		content1 := test.GetFileContent(t, used[0])
 		content2 := test.GetFileContent(t, used[1])
 
@@ -225,6 +225,121 @@ func TestExamineSnaps(t *testing.T) {
 		test.True(t, isDirty)
 	})
 
+	t.Run("should track named snapshots", func(t *testing.T) {
+		shouldUpdate, sort := true, false
+		dir := t.TempDir()
+		snapPath := filepath.Join(dir, "named.snap")
+		err := os.WriteFile(snapPath, []byte(`
+[TestNamed - 1]
+int(1)
+---
+
+[TestNamed - after-login]
+int(2)
+---
+
+[TestNamed - removed]
+int(3)
+---
+`), os.ModePerm)
+		test.NoError(t, err)
+
+		tests := map[string]map[string]int{snapPath: {"TestNamed": 1}}
+		named := map[string]set{snapPath: {"TestNamed - after-login": struct{}{}}}
+
+		obsolete, isDirty, err := examineSnaps(
+			tests,
+			named,
+			[]string{snapPath},
+			testFilter{},
+			1,
+			shouldUpdate,
+			sort,
+			nil,
+		)
+
+		test.NoError(t, err)
+		test.False(t, isDirty)
+		test.Equal(t, []string{"TestNamed - removed"}, obsolete)
+		test.Equal(
+			t,
+			"\n[TestNamed - 1]\nint(1)\n---\n\n[TestNamed - after-login]\nint(2)\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
+	t.Run("should preserve the file header", func(t *testing.T) {
+		shouldUpdate, sort := true, false
+		snapPath := filepath.Join(t.TempDir(), "header.snap")
+		err := os.WriteFile(
+			snapPath,
+			[]byte("# format: 2\n\n[TestHeader - 1]\n\\---\n---\n\n[TestRemoved - 1]\nint(3)\n---\n"),
+			os.ModePerm,
+		)
+		test.NoError(t, err)
+
+		tests := map[string]map[string]int{snapPath: {"TestHeader": 1}}
+
+		obsolete, _, err := examineSnaps(tests, nil, []string{snapPath}, testFilter{}, 1, shouldUpdate, sort, nil)
+
+		test.NoError(t, err)
+		test.Equal(t, []string{"TestRemoved - 1"}, obsolete)
+		test.Equal(
+			t,
+			"# format: 2\n\n[TestHeader - 1]\n\\---\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
+	t.Run("should preserve snapshot metadata", func(t *testing.T) {
+		shouldUpdate, sort := true, true
+		snapPath := filepath.Join(t.TempDir(), "metadata.snap")
+		err := os.WriteFile(
+			snapPath,
+			[]byte("# format: 2\n\n[TestMeta - 2]\n#@ serializer: go\nint(2)\n---\n\n"+
+				"[TestMeta - 1]\n#@ serializer: pretty\nint(1)\n---\n"),
+			os.ModePerm,
+		)
+		test.NoError(t, err)
+
+		tests := map[string]map[string]int{snapPath: {"TestMeta": 2}}
+
+		obsolete, _, err := examineSnaps(tests, nil, []string{snapPath}, testFilter{}, 1, shouldUpdate, sort, nil)
+
+		test.NoError(t, err)
+		test.Equal(t, []string{}, obsolete)
+		test.Equal(
+			t,
+			"# format: 2\n\n[TestMeta - 1]\n#@ serializer: pretty\nint(1)\n---\n\n"+
+				"[TestMeta - 2]\n#@ serializer: go\nint(2)\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
+	t.Run("should preserve CRLF line endings", func(t *testing.T) {
+		shouldUpdate, sort := true, true
+		snapPath := filepath.Join(t.TempDir(), "crlf.snap")
+		err := os.WriteFile(
+			snapPath,
+			[]byte("\r\n[TestCRLF - 2]\r\nint(2)\r\n---\r\n\r\n[TestCRLF - 1]\r\nint(1)\r\n---\r\n"+
+				"\r\n[TestRemoved - 1]\r\nint(3)\r\n---\r\n"),
+			os.ModePerm,
+		)
+		test.NoError(t, err)
+
+		tests := map[string]map[string]int{snapPath: {"TestCRLF": 2}}
+
+		obsolete, _, err := examineSnaps(tests, nil, []string{snapPath}, testFilter{}, 1, shouldUpdate, sort, nil)
+
+		test.NoError(t, err)
+		test.Equal(t, []string{"TestRemoved - 1"}, obsolete)
+		test.Equal(
+			t,
+			"\r\n[TestCRLF - 1]\r\nint(1)\r\n---\r\n\r\n[TestCRLF - 2]\r\nint(2)\r\n---\r\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
 	t.Run("should update the obsolete snap files", func(t *testing.T) {
 		shouldUpdate, sort := true, false
 		tests, dir1, dir2 := setupTempExamineFiles(
@@ -241,7 +356,7 @@ func TestExamineSnaps(t *testing.T) {
 		delete(tests[used[0]], "TestDir1_3/TestSimple")
 		delete(tests[used[1]], "TestDir2_1/TestSimple")
 
-		obsolete, isDirty, err := examineSnaps(tests, used, "", 1, shouldUpdate, sort)
+		obsolete, isDirty, err := examineSnaps(tests, nil, used, testFilter{}, 1, shouldUpdate, sort, nil)
 		content1 := test.GetFileContent(t, used[0])
 		content2 := test.GetFileContent(t, used[1])
 
@@ -303,7 +418,7 @@ string hello world 2 2 1
 			filepath.FromSlash(dir2 + "/test2.snap"),
 		}
 
-		obsolete, isDirty, err := examineSnaps(tests, used, "", 1, shouldUpdate, sort)
+		obsolete, isDirty, err := examineSnaps(tests, nil, used, testFilter{}, 1, shouldUpdate, sort, nil)
 
 		test.NoError(t, err)
 		test.Equal(t, 0, len(obsolete))
@@ -339,7 +454,7 @@ string hello world 2 2 1
 			delete(tests[used[0]], "TestDir1_3/TestSimple")
 			delete(tests[used[1]], "TestDir2_1/TestSimple")
 
-			obsolete, isDirty, err := examineSnaps(tests, used, "", 1, shouldUpdate, sort)
+			obsolete, isDirty, err := examineSnaps(tests, nil, used, testFilter{}, 1, shouldUpdate, sort, nil)
 
 			test.NoError(t, err)
 			test.Equal(
@@ -431,13 +546,16 @@ func TestOccurrences(t *testing.T) {
 }
 
 func TestSummary(t *testing.T) {
+	local := "update=new color=auto diff-context=3"
+	clean := "update=clean color=auto diff-context=3"
+
 	for _, v := range []struct {
 		name     string
 		snapshot string
 	}{
 		{
 			name:     "should print obsolete file",
-			snapshot: summary([]string{"test0.snap"}, nil, 0, nil, false),
+			snapshot: summary([]string{"test0.snap"}, nil, 0, nil, false, local),
 		},
 		{
 			name: "should print obsolete tests",
@@ -447,19 +565,20 @@ func TestSummary(t *testing.T) {
 				0,
 				nil,
 				false,
+				local,
 			),
 		},
 		{
 			name:     "should print updated file",
-			snapshot: summary([]string{"test0.snap"}, nil, 0, nil, true),
+			snapshot: summary([]string{"test0.snap"}, nil, 0, nil, true, clean),
 		},
 		{
 			name:     "should print updated test",
-			snapshot: summary(nil, []string{"TestMock/should_pass - 1"}, 0, nil, true),
+			snapshot: summary(nil, []string{"TestMock/should_pass - 1"}, 0, nil, true, clean),
 		},
 		{
 			name:     "should return empty string",
-			snapshot: summary(nil, nil, 0, nil, false),
+			snapshot: summary(nil, nil, 0, nil, false, local),
 		},
 		{
 			name: "should print events",
@@ -468,11 +587,11 @@ func TestSummary(t *testing.T) {
 				erred:   100,
 				updated: 3,
 				passed:  10,
-			}, false),
+			}, false, local),
 		},
 		{
 			name:     "should print number of skipped tests",
-			snapshot: summary(nil, nil, 1, nil, true),
+			snapshot: summary(nil, nil, 1, nil, true, clean),
 		},
 		{
 			name: "should print all summary",
@@ -487,6 +606,7 @@ func TestSummary(t *testing.T) {
 					passed:  10,
 				},
 				false,
+				"update=update (TestAPI/users/.*) color=auto diff-context=3",
 			),
 		},
 	} {
@@ -507,6 +627,11 @@ func TestGetTestID(t *testing.T) {
 		valid      bool
 	}{
 		{"[Test/something - 10]", "Test/something - 10", true},
+		{"[Test/something - after-login]", "Test/something - after-login", true},
+		{"[Test/something - v1.2_b]", "Test/something - v1.2_b", true},
+		{"[Test/something - 10]\r", "Test/something - 10", true},
+		{"[Test/something - ]", "", false},
+		{"[Test/something - -name]", "", false},
 		{input: "[Test/something - 100231231dsada]", expectedID: "", valid: false},
 		{input: "[Test/something - 100231231 ]", expectedID: "", valid: false},
 		{input: "[Test/something -100231231 ]", expectedID: "", valid: false},
@@ -524,6 +649,11 @@ func TestGetTestID(t *testing.T) {
 		// must have dash between test name and number
 		{"[Test something 10]", "", false},
 		{"[Test/something - not a number]", "", false},
+		// test names never contain spaces
+		{"[Test something - 10]", "", false},
+		{"[Test] started [worker - 1]", "", false},
+		// must have Test at the start, though other snapshot ids are parsed from snapshot files
+		{"[mock-name - 1]", "", false},
 		{"s", "", false},
 	}
 
@@ -2,7 +2,11 @@ package snaps
 
 import (
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 
+	"github.com/gkampitakis/go-snaps/normalize"
 	"github.com/tidwall/pretty"
 )
 
@@ -11,12 +15,21 @@ var defaultConfig = Config{
 }
 
 type Config struct {
-	filename   string
-	snapsDir   string
-	extension  string
-	update     *bool
-	json       *JSONConfig
-	serializer func(any) string
+	name            string
+	filename        string
+	snapsDir        string
+	extension       string
+	update          Policy
+	json            *JSONConfig
+	serializer      func(any) string
+	goSerializer    *GoSerializerConfig
+	typeSerializers map[reflect.Type]func(any) string
+	normalizers     []normalize.Normalizer
+	storage         Storage
+	deferred        bool
+	metadata        bool
+	frozen          bool
+	blobThreshold   int
 }
 
 type JSONConfig struct {
@@ -44,10 +57,24 @@ func (j *JSONConfig) getPrettyJSONOptions() *pretty.Options {
 
 // Update determines whether to update snapshots or not
 //
-// It respects if running on CI.
+// It respects if running on CI. Update(true) is the same as UpdatePolicy(PolicyUpdate)
+// and Update(false) as UpdatePolicy(PolicyNever).
 func Update(u bool) func(*Config) {
 	return func(c *Config) {
-		c.update = &u
+		c.update = PolicyNever
+		if u {
+			c.update = PolicyUpdate
+		}
+	}
+}
+
+// UpdatePolicy sets the policy determining which snapshots are created and updated,
+// overriding the UPDATE_SNAPS env variable unless it's set to always.
+//
+//	snaps.WithConfig(snaps.UpdatePolicy(snaps.PolicyNew))
+func UpdatePolicy(p Policy) func(*Config) {
+	return func(c *Config) {
+		c.update = p
 	}
 }
 
@@ -60,6 +87,46 @@ func Serializer(s func(any) string) func(*Config) {
 	}
 }
 
+// GoSerializer sets go-snaps' deterministic Go value serializer for converting the received value
+// to a string instead of the default kr/pretty formatting.
+//
+// It sorts map keys, never prints memory addresses, detects cycles and renders
+// time.Time, time.Duration, big.Int and net.IP in a readable form.
+//
+// Note: this is only used for non-structured snapshots e.g. MatchSnapshot, MatchStandaloneSnapshot, MatchInlineSnapshot.
+func GoSerializer(config GoSerializerConfig) func(*Config) {
+	return func(c *Config) {
+		c.goSerializer = &config
+	}
+}
+
+/*
+TypeSerializer sets a serializer for values of type T used by snaps.GoSerializer.
+
+Whenever the Go value serializer meets a value of type T, at any depth, it uses the given
+function instead of the default rendering.
+
+	snaps.WithConfig(
+		snaps.GoSerializer(snaps.GoSerializerConfig{}),
+		snaps.TypeSerializer(func(id uuid.UUID) string {
+			return id.String()
+		}),
+	).MatchSnapshot(t, user)
+
+It takes precedence over serializers registered globally with snaps.RegisterSerializer.
+*/
+func TypeSerializer[T any](fn func(T) string) func(*Config) {
+	return func(c *Config) {
+		types := maps.Clone(c.typeSerializers)
+		if types == nil {
+			types = make(map[reflect.Type]func(any) string)
+		}
+		types[reflect.TypeFor[T]()] = wrapTypeSerializer(fn)
+
+		c.typeSerializers = types
+	}
+}
+
 // Raw is a utility function for setting serializer to fmt.Sprint
 //
 // For more complex custom serialization logic, use snaps.Serializer instead of snaps.Raw
@@ -71,6 +138,142 @@ func Raw() func(*Config) {
 	}
 }
 
+/*
+Normalize adds normalizers that transform the serialized value before it is compared and stored.
+Normalizers run in the order they are passed, after any previously configured ones.
+
+	snaps.WithConfig(snaps.Normalize(
+		normalize.TempDir(),
+		normalize.Duration(),
+		normalize.Regex(`host: \S+`, "host: <HOST>"),
+	)).MatchSnapshot(t, output)
+
+Note: this is only used for non-structured snapshots e.g. MatchSnapshot, MatchStandaloneSnapshot, MatchInlineSnapshot.
+*/
+func Normalize(normalizers ...normalize.Normalizer) func(*Config) {
+	return func(c *Config) {
+		c.normalizers = append(slices.Clip(c.normalizers), normalizers...)
+	}
+}
+
+/*
+Name stores the snapshot under an explicit name instead of the occurrence counter.
+
+	snaps.WithConfig(snaps.Name("after-login")).MatchSnapshot(t, page)
+
+creates the entry [TestName - after-login], so adding or reordering snapshot calls in a test
+doesn't affect it.
+
+The name must start with a letter and contain only letters, digits, '_', '.' or '-'
+and can be used only once per test.
+*/
+func Name(name string) func(*Config) {
+	return func(c *Config) {
+		c.name = name
+	}
+}
+
+/*
+StorageBackend sets where snapshot files are read from and written to, instead of the OS filesystem.
+
+	snaps.WithConfig(snaps.StorageBackend(snaps.NewMemoryStorage())).MatchSnapshot(t, value)
+
+go-snaps ships snaps.NewMemoryStorage and the read-only snaps.NewFSStorage (e.g. for an embed.FS).
+A snapshot directory should be used with a single storage, as snaps.Clean examines each directory
+with the storage last used for it.
+*/
+func StorageBackend(s Storage) func(*Config) {
+	return func(c *Config) {
+		c.storage = s
+	}
+}
+
+/*
+DeferWrites buffers snapshot additions and updates in memory and writes each snapshot file once,
+instead of rewriting it on every call.
+
+Buffered writes are flushed by snaps.Clean, snaps.Flush or when no test using snaps.DeferWrites
+is running anymore, so use snaps.Clean or snaps.Flush for writing each file once when tests
+don't run in parallel. The written snapshot files are identical to the ones written on every call.
+
+Note: it only applies to the snapshots matched with this config, a write without it also writes
+the changes buffered for the same snapshot file. It is not used for standalone snapshots.
+*/
+func DeferWrites() func(*Config) {
+	return func(c *Config) {
+		c.deferred = true
+	}
+}
+
+/*
+Metadata stores metadata along with the snapshots e.g. the serializer or the matchers used,
+making snapshot files self-describing.
+
+Snapshot files written with metadata get a header with the format version, the go-snaps version
+and the serializer and json options used.
+
+	# format: 2
+	# go-snaps: v0.5.13
+	# serializer: pretty
+	# json: width=0 indent=" " sort-keys=true
+
+	[TestUser - 1]
+	#@ json: width=0 indent=" " sort-keys=true
+	#@ matchers: match.Any("created_at")
+	{
+	 "created_at": "<Any value>"
+	}
+	---
+*/
+func Metadata() func(*Config) {
+	return func(c *Config) {
+		c.metadata = true
+	}
+}
+
+/*
+Frozen marks the snapshots as frozen, for snapshots that must never change silently e.g. public API responses.
+
+Mismatches on frozen snapshots always fail, even with UPDATE_SNAPS=always. New snapshots are stored with
+a marker, so they stay frozen without the option and are never removed by snaps.Clean.
+
+	[TestPublicAPI - 1]
+	#@ frozen: true
+	<snapshot>
+	---
+
+Snapshots can also be frozen by adding the `#@ frozen: true` line after their id in a snapshot file
+with a `# format: 2` header.
+
+Standalone and inline snapshots have no metadata to store the marker in, so they are only frozen
+while the option is used.
+*/
+func Frozen() func(*Config) {
+	return func(c *Config) {
+		c.frozen = true
+	}
+}
+
+/*
+BlobThreshold sets the size in bytes above which snapshots are stored in a separate file, so large
+snapshots e.g. rendered reports don't bloat the snapshot file.
+
+The snapshot is stored in `__snapshots__/blobs/<sha256>.snap` and the snapshot file keeps the test id
+with a reference to it.
+
+	[TestReport - 1]
+	#@ blob: blobs/9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08.snap
+
+	---
+
+Blobs no longer referenced are removed by snaps.Clean.
+*/
+func BlobThreshold(size int) func(*Config) {
+	return func(c *Config) {
+		c.blobThreshold = size
+	}
+}
+
 // Specify snapshot file name
 //
 //	default: test's filename
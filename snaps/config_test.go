@@ -2,9 +2,11 @@ package snaps
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/gkampitakis/go-snaps/internal/test"
+	"github.com/gkampitakis/go-snaps/normalize"
 )
 
 func TestWithConfig(t *testing.T) {
@@ -14,7 +16,7 @@ func TestWithConfig(t *testing.T) {
 		test.Equal(t, "__snapshots__", c.snapsDir)
 		test.Equal(t, "", c.filename)
 		test.Equal(t, "", c.extension)
-		test.Nil(t, c.update)
+		test.Equal(t, "", c.update)
 		test.Nil(t, c.json)
 		test.Nil(t, c.serializer)
 	})
@@ -36,10 +38,15 @@ func TestWithConfig(t *testing.T) {
 
 	t.Run("Update", func(t *testing.T) {
 		c := WithConfig(Update(true))
-		test.Equal(t, true, *c.update)
+		test.Equal(t, PolicyUpdate, c.update)
 
 		c = WithConfig(Update(false))
-		test.Equal(t, false, *c.update)
+		test.Equal(t, PolicyNever, c.update)
+	})
+
+	t.Run("UpdatePolicy", func(t *testing.T) {
+		c := WithConfig(UpdatePolicy(PolicyNew))
+		test.Equal(t, PolicyNew, c.update)
 	})
 
 	t.Run("JSON", func(t *testing.T) {
@@ -55,12 +62,26 @@ func TestWithConfig(t *testing.T) {
 		test.Equal(t, "hello", c.serializer("hello"))
 	})
 
+	t.Run("GoSerializer", func(t *testing.T) {
+		c := WithConfig(GoSerializer(GoSerializerConfig{HideUnexported: true}))
+		test.True(t, c.goSerializer.HideUnexported)
+		test.False(t, c.goSerializer.HideZero)
+	})
+
+	t.Run("Normalize", func(t *testing.T) {
+		base := WithConfig(Normalize(normalize.HexAddress()))
+		c := WithConfig(Normalize(normalize.HexAddress()), Normalize(normalize.Duration()))
+
+		test.Equal(t, 1, len(base.normalizers))
+		test.Equal(t, 2, len(c.normalizers))
+	})
+
 	t.Run("multiple options are all applied", func(t *testing.T) {
 		c := WithConfig(Filename("my_test"), Dir("my_dir"), Ext(".txt"), Update(true))
 		test.Equal(t, "my_test", c.filename)
 		test.Equal(t, "my_dir", c.snapsDir)
 		test.Equal(t, ".txt", c.extension)
-		test.Equal(t, true, *c.update)
+		test.Equal(t, PolicyUpdate, c.update)
 	})
 
 	t.Run("does not mutate defaultConfig", func(t *testing.T) {
@@ -68,7 +89,7 @@ func TestWithConfig(t *testing.T) {
 
 		test.Equal(t, "__snapshots__", defaultConfig.snapsDir)
 		test.Equal(t, "", defaultConfig.filename)
-		test.Nil(t, defaultConfig.update)
+		test.Equal(t, "", defaultConfig.update)
 		test.Nil(t, defaultConfig.serializer)
 	})
 }
@@ -110,6 +131,37 @@ func TestTakeSnapshot(t *testing.T) {
 
 		test.Equal(t, "[1 2 3]", result)
 	})
+
+	t.Run("uses go serializer", func(t *testing.T) {
+		c := WithConfig(GoSerializer(GoSerializerConfig{}))
+
+		result := c.takeSnapshot([]any{10, "hello world", map[string]int{"b": 2, "a": 1}})
+
+		test.Equal(t, "int(10)\nhello world\nmap[string]int{\n    \"a\": 1,\n    \"b\": 2,\n}", result)
+	})
+
+	t.Run("applies normalizers in order after serialization", func(t *testing.T) {
+		c := WithConfig(
+			Raw(),
+			Normalize(
+				normalize.Duration(),
+				normalize.Regex("<DURATION>", "<D>"),
+				normalize.Func(strings.ToUpper),
+			),
+		)
+
+		result := c.takeSnapshot([]any{"took 12ms", "took 1.5s"})
+
+		test.Equal(t, "TOOK <D>\nTOOK <D>", result)
+	})
+
+	t.Run("custom serializer takes precedence over go serializer", func(t *testing.T) {
+		c := WithConfig(GoSerializer(GoSerializerConfig{}), Raw())
+
+		result := c.takeSnapshot([]any{[]int{1, 2, 3}})
+
+		test.Equal(t, "[1 2 3]", result)
+	})
 }
 
 func TestTakeStandaloneSnapshot(t *testing.T) {
@@ -136,6 +188,13 @@ func TestTakeStandaloneSnapshot(t *testing.T) {
 
 		test.Equal(t, "[1 2 3]", result)
 	})
+	t.Run("applies normalizers", func(t *testing.T) {
+		c := WithConfig(Normalize(normalize.HexAddress()))
+
+		result := c.takeStandaloneSnapshot("ptr 0xc000012345")
+
+		test.Equal(t, "ptr <HEX_ADDRESS>", result)
+	})
 }
 
 func TestTakeInlineSnapshot(t *testing.T) {
@@ -16,7 +16,6 @@ const (
 	diffEqual  diffmatchpatch.Operation = 0
 	diffInsert diffmatchpatch.Operation = 1
 	diffDelete diffmatchpatch.Operation = -1
-	context                             = 3
 )
 
 var dmp = diffmatchpatch.New()
@@ -47,7 +46,8 @@ func shouldPrintHighlights(a, b string) bool {
 // Compare two sequences of lines; generate the delta as a unified diff.
 //
 // Unified diffs are a compact way of showing line changes and a few
-// lines of context. The number of context lines is set by default to three.
+// lines of context. The number of context lines is set by default to three,
+// and can be changed with the -snaps.diff-context flag.
 //
 // getUnifiedDiff returns a diff string along with inserted and deleted number.
 func getUnifiedDiff(a, b string) (string, int, int) {
@@ -61,7 +61,7 @@ func getUnifiedDiff(a, b string) (string, int, int) {
 	s.Grow(len(a) + len(b))
 
 	m := difflib.NewMatcher(aLines, bLines)
-	for _, g := range m.GetGroupedOpCodes(context) {
+	for _, g := range m.GetGroupedOpCodes(diffContext) {
 		// aLines is a product of splitNewLines(), some items are just \"n"
 		// if change is less than 10 items don't print the range
 		if len(aLines) > 10 || len(bLines) > 10 {
@@ -0,0 +1,275 @@
+package snaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gkampitakis/go-snaps/internal/colors"
+)
+
+/*
+In dry-run mode, enabled with SNAPS_DRY_RUN=text|json or -snaps.dry-run, snapshots are never written.
+Snapshots that would be added or updated are recorded in a plan instead, which snaps.Clean completes
+with the obsolete snapshots and files it would remove or sort, even when the update policy doesn't
+remove them, and writes as text or json to stdout or SNAPS_DRY_RUN_OUTPUT.
+*/
+const (
+	dryRunText = "text"
+	dryRunJSON = "json"
+
+	// previewLines is the number of lines of removed snapshots shown in the plan
+	previewLines = 3
+)
+
+var (
+	dryRunFormat = os.Getenv("SNAPS_DRY_RUN")
+	dryRunOutput = os.Getenv("SNAPS_DRY_RUN_OUTPUT")
+	plan         = newDryRunPlan()
+	plannedMsg   = colors.Sprint(colors.Yellow, updateSymbol+"Snapshot planned (dry run)")
+)
+
+func isDryRun() bool {
+	return dryRunFormat != ""
+}
+
+// dryRunPlan is the report of the changes go-snaps would make to snapshots.
+type dryRunPlan struct {
+	Settings string `json:"settings"`
+	// Clean is whether the active update policy removes, deletes and sorts snapshots,
+	// otherwise Removed, Deleted and Sorted are only applied with UPDATE_SNAPS=clean
+	Clean bool `json:"clean"`
+	// Added are the snapshots that would be created
+	Added []plannedSnapshot `json:"added"`
+	// Updated are the snapshots that would be updated, along with their diff
+	Updated []plannedSnapshot `json:"updated"`
+	// Removed are the obsolete snapshots that would be removed, along with their first lines
+	Removed []plannedSnapshot `json:"removed"`
+	// Deleted are the obsolete snapshot files and blobs that would be deleted
+	Deleted []string `json:"deleted"`
+	// Sorted are the snapshot files that would be sorted
+	Sorted []plannedSort `json:"sorted"`
+
+	sync.Mutex
+}
+
+type plannedSnapshot struct {
+	File    string `json:"file"`
+	ID      string `json:"id"`
+	Line    int    `json:"line,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+	Preview string `json:"preview,omitempty"`
+
+	prev, snapshot string
+}
+
+type plannedSort struct {
+	File string `json:"file"`
+	// Moved are the ids of the snapshots changing position
+	Moved []string `json:"moved"`
+}
+
+func newDryRunPlan() *dryRunPlan {
+	return &dryRunPlan{
+		Added:   []plannedSnapshot{},
+		Updated: []plannedSnapshot{},
+		Removed: []plannedSnapshot{},
+		Deleted: []string{},
+		Sorted:  []plannedSort{},
+	}
+}
+
+func (p *dryRunPlan) empty() bool {
+	return len(p.Added)+len(p.Updated)+len(p.Removed)+len(p.Deleted)+len(p.Sorted) == 0
+}
+
+// planSnapshot records in dry-run mode the snapshot that would be added, when prev is nil,
+// or updated instead of writing it. It returns false when not in dry-run mode.
+func planSnapshot(t testingT, file, id string, line int, prev *string, snapshot string) bool {
+	t.Helper()
+	if !isDryRun() {
+		return false
+	}
+
+	plan.Lock()
+	defer plan.Unlock()
+
+	s := plannedSnapshot{File: relPath(file), ID: strings.Trim(id, "[]"), Line: line, snapshot: snapshot}
+	if prev == nil {
+		plan.Added = append(plan.Added, s)
+	} else {
+		s.prev = *prev
+		plan.Updated = append(plan.Updated, s)
+	}
+
+	t.Log(plannedMsg)
+	return true
+}
+
+// planRemoved records the obsolete snapshot entry of the snapshot file, with its first lines.
+func (p *dryRunPlan) planRemoved(snapPath, testID string, line int, snapshot string) {
+	lines := strings.SplitAfter(snapshot, "\n")
+	if len(lines) > previewLines {
+		lines = append(lines[:previewLines], "...")
+	}
+
+	p.Removed = append(p.Removed, plannedSnapshot{
+		File:    relPath(snapPath),
+		ID:      testID,
+		Line:    line,
+		Preview: strings.Join(lines, ""),
+	})
+}
+
+// planSorted records the snapshot ids changing position when the snapshot file is sorted.
+func (p *dryRunPlan) planSorted(snapPath string, ids []string) {
+	sorted := slices.Clone(ids)
+	slices.SortStableFunc(sorted, naturalSort)
+
+	moved := []string{}
+	for i := range ids {
+		if ids[i] != sorted[i] {
+			moved = append(moved, ids[i])
+		}
+	}
+
+	p.Sorted = append(p.Sorted, plannedSort{File: relPath(snapPath), Moved: moved})
+}
+
+// write renders the plan in the dry-run format to the dry-run output, stdout by default.
+func (p *dryRunPlan) write() error {
+	var w io.Writer = os.Stdout
+	if dryRunOutput != "" {
+		f, err := os.Create(dryRunOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch dryRunFormat {
+	case dryRunJSON:
+		// diffs in json are never colored
+		noColor := colors.NOCOLOR
+		colors.NOCOLOR = true
+		p.diffs()
+		colors.NOCOLOR = noColor
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	case dryRunText:
+		p.diffs()
+		_, err := io.WriteString(w, p.String())
+		return err
+	default:
+		return fmt.Errorf("invalid dry run format %q, must be text or json", dryRunFormat)
+	}
+}
+
+func (p *dryRunPlan) diffs() {
+	for i, s := range p.Updated {
+		p.Updated[i].Diff = prettyDiff(s.prev, s.snapshot, s.File, s.Line)
+	}
+}
+
+// String renders the plan as text.
+func (p *dryRunPlan) String() string {
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "\n%s\n\n", colors.Sprint(colors.BoldWhite, "Snapshot Dry Run"))
+	fmt.Fprintf(&s, "%s\n", colors.Sprint(colors.Dim, "Settings: "+p.Settings))
+
+	if p.empty() {
+		colors.Fprint(&s, colors.Green, fmt.Sprintf("\n%sno changes\n", successSymbol))
+		return s.String()
+	}
+
+	header := func(n int, subject, action string) {
+		if n > 1 {
+			subject += "s"
+		}
+		colors.Fprint(&s, colors.Yellow, fmt.Sprintf("\n%s%d %s would be %s\n", arrowSymbol, n, subject, action))
+	}
+	// obsolete snapshots and files are only removed and sorted by policies that clean
+	cleanAction := func(action string) string {
+		if p.Clean {
+			return action
+		}
+		return action + " with UPDATE_SNAPS=clean"
+	}
+	item := func(text string) {
+		colors.Fprint(&s, colors.Dim, fmt.Sprintf("  %s %s%s\n", enterSymbol, bulletSymbol, text))
+	}
+	at := func(snap plannedSnapshot) string {
+		if snap.Line > 0 {
+			return fmt.Sprintf("[%s] at %s:%d", snap.ID, snap.File, snap.Line)
+		}
+		return fmt.Sprintf("[%s] at %s", snap.ID, snap.File)
+	}
+
+	if len(p.Added) > 0 {
+		header(len(p.Added), "snapshot", "added")
+		for _, snap := range p.Added {
+			item(at(snap))
+		}
+	}
+	if len(p.Updated) > 0 {
+		header(len(p.Updated), "snapshot", "updated")
+		for _, snap := range p.Updated {
+			item(at(snap))
+			s.WriteString(snap.Diff)
+		}
+	}
+	if len(p.Removed) > 0 {
+		header(len(p.Removed), "snapshot", cleanAction("removed"))
+		for _, snap := range p.Removed {
+			item(at(snap))
+			for _, line := range strings.SplitAfter(strings.TrimSuffix(snap.Preview, "\n"), "\n") {
+				fmt.Fprintf(&s, "      %s\n", strings.TrimSuffix(line, "\n"))
+			}
+		}
+	}
+	if len(p.Deleted) > 0 {
+		header(len(p.Deleted), "snapshot file", cleanAction("deleted"))
+		for _, file := range p.Deleted {
+			item(file)
+		}
+	}
+	if len(p.Sorted) > 0 {
+		header(len(p.Sorted), "snapshot file", cleanAction("sorted"))
+		for _, sorted := range p.Sorted {
+			item(fmt.Sprintf("%s, moving %s", sorted.File, strings.Join(sorted.Moved, ", ")))
+		}
+	}
+
+	return s.String()
+}
+
+func relPaths(paths []string) []string {
+	rel := make([]string, len(paths))
+	for i, path := range paths {
+		rel[i] = relPath(path)
+	}
+
+	return rel
+}
+
+// relPath returns path relative to the working directory, for reporting.
+func relPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return path
+}
@@ -0,0 +1,188 @@
+package snaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/colors"
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+const dryRunFilename = "dryrun_test.snap"
+
+func setupDryRun(t *testing.T, format string) {
+	t.Helper()
+	dryRunFormat = format
+	t.Cleanup(func() {
+		dryRunFormat = ""
+		plan = newDryRunPlan()
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	t.Run("should plan new snapshots without writing them", func(t *testing.T) {
+		snapPath := setupSnapshot(t, dryRunFilename, false)
+		setupDryRun(t, dryRunText)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, plannedMsg, args[0].(string)) }
+		MatchSnapshot(mockT, "hello world")
+
+		_, err := os.Stat(snapPath)
+		test.True(t, os.IsNotExist(err))
+		test.Equal(t, 1, len(plan.Added))
+		test.Equal(t, "mock-name - 1", plan.Added[0].ID)
+		test.Equal(t, relPath(snapPath), plan.Added[0].File)
+		test.Equal(t, 0, testEvents.items[added])
+	})
+
+	t.Run("should plan updated snapshots with their diff", func(t *testing.T) {
+		snapPath := setupSnapshot(t, dryRunFilename, true, "update")
+		test.NoError(t, os.WriteFile(snapPath, []byte("\n[mock-name - 1]\nhello world\n---\n"), 0o644))
+		// dry runs respect updates on CI as they write nothing
+		setupDryRun(t, dryRunJSON)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, plannedMsg, args[0].(string)) }
+		MatchSnapshot(mockT, "bye world")
+
+		test.Equal(t, "\n[mock-name - 1]\nhello world\n---\n", test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, len(plan.Updated))
+		test.Equal(t, 2, plan.Updated[0].Line)
+
+		noColor := colors.NOCOLOR
+		colors.NOCOLOR = true
+		t.Cleanup(func() { colors.NOCOLOR = noColor })
+		plan.diffs()
+		test.Contains(t, plan.Updated[0].Diff, "- hello world")
+		test.Contains(t, plan.Updated[0].Diff, "+ bye world")
+	})
+
+	t.Run("should not write pending snapshots", func(t *testing.T) {
+		snapPath := setupSnapshot(t, dryRunFilename, false, "pending")
+		setupDryRun(t, dryRunText)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+		MatchSnapshot(mockT, "hello world")
+
+		_, err := os.Stat(snapPath + pendingExt)
+		test.True(t, os.IsNotExist(err))
+		test.Equal(t, 1, len(plan.Added))
+	})
+
+	t.Run("should plan removed and sorted snapshots", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "plan.snap")
+		content := "\n[TestB - 1]\nb\n---\n\n[TestA - 1]\na\n---\n\n[TestOld - 1]\n1\n2\n3\n4\n---\n"
+		test.NoError(t, os.WriteFile(snapPath, []byte(content), 0o644))
+		tests := map[string]map[string]int{snapPath: {"TestA": 1, "TestB": 1}}
+		p := newDryRunPlan()
+
+		obsolete, isDirty, err := examineSnaps(tests, nil, []string{snapPath}, testFilter{}, 1, false, true, p)
+
+		test.NoError(t, err)
+		test.True(t, isDirty)
+		test.Equal(t, []string{"TestOld - 1"}, obsolete)
+		test.Equal(t, content, test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, len(p.Removed))
+		test.Equal(t, "TestOld - 1", p.Removed[0].ID)
+		test.Equal(t, 10, p.Removed[0].Line)
+		test.Equal(t, "1\n2\n3\n...", p.Removed[0].Preview)
+		test.Equal(t, []plannedSort{{File: snapPath, Moved: []string{"TestB - 1", "TestA - 1"}}}, p.Sorted)
+	})
+
+	t.Run("should write the plan as json", func(t *testing.T) {
+		setupDryRun(t, dryRunJSON)
+		dryRunOutput = filepath.Join(t.TempDir(), "plan.json")
+		t.Cleanup(func() { dryRunOutput = "" })
+
+		plan.Settings = "update=clean"
+		plan.Added = append(plan.Added, plannedSnapshot{File: "a.snap", ID: "TestA - 1"})
+		plan.Updated = append(plan.Updated, plannedSnapshot{
+			File: "a.snap", ID: "TestB - 1", Line: 5, prev: "b", snapshot: "c",
+		})
+		plan.Deleted = append(plan.Deleted, "old.snap")
+		test.NoError(t, plan.write())
+
+		var got map[string]any
+		test.NoError(t, json.Unmarshal([]byte(test.GetFileContent(t, dryRunOutput)), &got))
+		test.Equal(t, "update=clean", got["settings"].(string))
+		test.Equal(t, 1, len(got["added"].([]any)))
+		test.Equal(t, []any{"old.snap"}, got["deleted"].([]any))
+		test.Equal(t, 0, len(got["removed"].([]any)))
+
+		updated := got["updated"].([]any)[0].(map[string]any)
+		test.Equal(t, "TestB - 1", updated["id"].(string))
+		test.Equal(t, "\n- Snapshot - 1\n+ Received + 1\n\n- b\n+ c\n\nat a.snap:5\n", updated["diff"].(string))
+	})
+
+	t.Run("should render the plan as text", func(t *testing.T) {
+		noColor := colors.NOCOLOR
+		colors.NOCOLOR = true
+		t.Cleanup(func() { colors.NOCOLOR = noColor })
+
+		p := newDryRunPlan()
+		p.Settings = "update=clean color=never diff-context=3 dry-run=text"
+		p.Clean = true
+		test.Equal(
+			t,
+			"\nSnapshot Dry Run\n\nSettings: update=clean color=never diff-context=3 dry-run=text\n\n✓ no changes\n",
+			p.String(),
+		)
+
+		p.Added = append(p.Added, plannedSnapshot{File: "a.snap", ID: "TestA - 1"})
+		p.Removed = append(p.Removed, plannedSnapshot{File: "a.snap", ID: "TestOld - 1", Line: 9, Preview: "1\n2\n"})
+		p.Sorted = append(p.Sorted, plannedSort{File: "a.snap", Moved: []string{"TestB - 1", "TestA - 1"}})
+		test.Equal(
+			t,
+			"\nSnapshot Dry Run\n\nSettings: update=clean color=never diff-context=3 dry-run=text\n"+
+				"\n› 1 snapshot would be added\n  ↳  • [TestA - 1] at a.snap\n"+
+				"\n› 1 snapshot would be removed\n  ↳  • [TestOld - 1] at a.snap:9\n      1\n      2\n"+
+				"\n› 1 snapshot file would be sorted\n  ↳  • a.snap, moving TestB - 1, TestA - 1\n",
+			p.String(),
+		)
+
+		p.Clean = false
+		test.Contains(t, p.String(), "› 1 snapshot would be removed with UPDATE_SNAPS=clean\n")
+		test.Contains(t, p.String(), "› 1 snapshot file would be sorted with UPDATE_SNAPS=clean\n")
+	})
+
+	t.Run("should plan obsolete snapshots from Clean whatever the policy", func(t *testing.T) {
+		setupSnapshot(t, dryRunFilename, false)
+		setupDryRun(t, dryRunJSON)
+		dryRunOutput = filepath.Join(t.TempDir(), "plan.json")
+		t.Cleanup(func() { dryRunOutput = "" })
+
+		// ids are prefixed with the test name, so they aren't skipped by -run
+		dir := t.TempDir()
+		snapPath := filepath.Join(dir, "clean_test.snap")
+		obsoletePath := filepath.Join(dir, "obsolete_test.snap")
+		content := fmt.Sprintf(
+			"\n[%[1]s/b - 1]\nb\n---\n\n[%[1]s/a - 1]\na\n---\n\n[%[1]s/old - 1]\nold\n---\n",
+			t.Name(),
+		)
+		test.NoError(t, os.WriteFile(snapPath, []byte(content), 0o644))
+		test.NoError(t, os.WriteFile(obsoletePath, []byte(""), 0o644))
+		testsRegistry.cleanup[snapPath] = map[string]int{t.Name() + "/a": 1, t.Name() + "/b": 1}
+
+		dirty, err := Clean(nil, CleanOpts{Sort: true})
+
+		test.NoError(t, err)
+		test.True(t, dirty)
+		test.Equal(t, content, test.GetFileContent(t, snapPath))
+		test.Equal(t, "", test.GetFileContent(t, obsoletePath))
+		test.False(t, plan.Clean)
+		test.Equal(t, 1, len(plan.Removed))
+		test.Equal(t, t.Name()+"/old - 1", plan.Removed[0].ID)
+		test.Equal(t, []string{obsoletePath}, plan.Deleted)
+		test.Equal(t, 1, len(plan.Sorted))
+
+		var got map[string]any
+		test.NoError(t, json.Unmarshal([]byte(test.GetFileContent(t, dryRunOutput)), &got))
+		test.False(t, got["clean"].(bool))
+		test.Equal(t, 1, len(got["removed"].([]any)))
+	})
+}
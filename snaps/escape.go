@@ -0,0 +1,68 @@
+package snaps
+
+import (
+	"strings"
+)
+
+const (
+	// legacyFormat is the format of snapshot files without a header. Only the end sequence
+	// is escaped, as legacyEscapedEndSequence, which isn't reversible.
+	legacyFormat = 1
+	// escapedFormat escapes every line that looks like a marker by prefixing it with a '\'.
+	escapedFormat = 2
+
+	legacyEscapedEndSequence = "/-/-/-/"
+	escapeChar               = `\`
+)
+
+// isMarkerLike reports whether line, ignoring any leading escape chars, is an end sequence,
+// a test id or a metadata line, so it needs escaping inside a snapshot.
+func isMarkerLike(line string) bool {
+	l := strings.TrimLeft(line, escapeChar)
+	return l == endSequence || isTestIDLine([]byte(l)) || strings.HasPrefix(l, metadataMarker)
+}
+
+// needsEscaping reports whether the snapshot is stored differently depending on the format.
+// Snapshots not needing escaping can be stored in legacy format files.
+func needsEscaping(snapshot string) bool {
+	for _, l := range strings.Split(snapshot, "\n") {
+		if l == legacyEscapedEndSequence || isMarkerLike(l) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// escapeSnapshot escapes the snapshot for storing it in a snapshot file of the given format.
+//
+// In escapedFormat every line looking like a marker gets one more leading '\', so the escaping
+// is reversible even for lines that are already escaped.
+func escapeSnapshot(snapshot string, format int) string {
+	ss := strings.Split(snapshot, "\n")
+	for idx, l := range ss {
+		switch {
+		case format == legacyFormat && l == endSequence:
+			ss[idx] = legacyEscapedEndSequence
+		case format == escapedFormat && isMarkerLike(l):
+			ss[idx] = escapeChar + l
+		}
+	}
+
+	return strings.Join(ss, "\n")
+}
+
+// unescapeSnapshot reverses escapeSnapshot.
+func unescapeSnapshot(snapshot string, format int) string {
+	ss := strings.Split(snapshot, "\n")
+	for idx, l := range ss {
+		switch {
+		case format == legacyFormat && l == legacyEscapedEndSequence:
+			ss[idx] = endSequence
+		case format == escapedFormat && isMarkerLike(l) && strings.HasPrefix(l, escapeChar):
+			ss[idx] = l[1:]
+		}
+	}
+
+	return strings.Join(ss, "\n")
+}
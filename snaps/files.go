@@ -0,0 +1,212 @@
+package snaps
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// FileSnapshot is a snapshot inside a snapshot file.
+type FileSnapshot struct {
+	// ID is the snapshot id without the brackets e.g. "TestName - 1"
+	ID string
+	// Line is the line of the snapshot id in the file
+	Line int
+}
+
+// ReadSnapshots returns the snapshots of the snapshot file in the order they are stored.
+//
+// Standalone snapshot files have no snapshot ids, so no snapshots are returned for them.
+func ReadSnapshots(path string) ([]FileSnapshot, error) {
+	data, err := storageFor(path).Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := parseSnapshotFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+	snapshots := make([]FileSnapshot, 0, len(f.order))
+	for _, e := range f.order {
+		snapshots = append(snapshots, FileSnapshot{ID: entryID(e), Line: e.line})
+	}
+
+	return snapshots, nil
+}
+
+// SortFile sorts the snapshots of the snapshot file by their ids, same as snaps.Clean with CleanOpts.Sort.
+func SortFile(path string) error {
+	return rewriteFile(path, func(f *snapshotFile) []*indexEntry {
+		entries := slices.Clone(f.order)
+		slices.SortStableFunc(entries, func(a, b *indexEntry) int {
+			return naturalSort(entryID(a), entryID(b))
+		})
+
+		return entries
+	})
+}
+
+// PruneFile removes from the snapshot file the snapshots of the tests with names matching test,
+// returning the ids of the removed snapshots. Frozen snapshots are never removed.
+func PruneFile(path string, test *regexp.Regexp) ([]string, error) {
+	removed := []string{}
+	err := rewriteFile(path, func(f *snapshotFile) []*indexEntry {
+		entries := make([]*indexEntry, 0, len(f.order))
+		for _, e := range f.order {
+			id := entryID(e)
+			testName := id
+			if i := strings.LastIndex(id, " - "); i != -1 {
+				testName = id[:i]
+			}
+
+			if test.MatchString(testName) && !isFrozen(f.metadata(e)) {
+				removed = append(removed, id)
+				continue
+			}
+			entries = append(entries, e)
+		}
+
+		return entries
+	})
+
+	return removed, err
+}
+
+// entryID returns the snapshot id without the brackets.
+func entryID(e *indexEntry) string {
+	if id, ok := getTestID([]byte(e.id)); ok {
+		return id
+	}
+
+	return strings.Trim(e.id, "[]")
+}
+
+// rewriteFile writes the snapshot file with the entries returned from fn, if they changed.
+// Files with content outside of snapshots, like standalone snapshot files, are left untouched.
+func rewriteFile(path string, fn func(f *snapshotFile) []*indexEntry) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	storage := storageFor(path)
+	data, err := storage.Read(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := parseSnapshotFile(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, path)
+	}
+	// standalone snapshot files, or anything else outside of snapshots, would be lost when rendered
+	if len(f.order) == 0 || f.foreign {
+		return nil
+	}
+
+	out := f.render(fn(f))
+	if bytes.Equal(out, data) {
+		return nil
+	}
+
+	snapshotFiles.invalidate(path)
+	return storage.Write(path, out)
+}
+
+/*
+CheckFile validates the syntax of the snapshot file, returning an error for every problem found
+in the form "<path>:<line>: <problem>".
+
+It reports unsupported file formats, content outside of snapshots, invalid or duplicate snapshot ids, snapshots missing
+the `---` end sequence and references to missing blobs. Standalone snapshot files, without
+snapshot ids, are not checked.
+*/
+func CheckFile(path string) error {
+	storage := storageFor(path)
+	data, err := storage.Read(path)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	report := func(line int, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s:%d: %s", path, line, fmt.Sprintf(format, args...)))
+	}
+
+	header, format, headerEnd, err := parseHeader(data)
+	if err != nil {
+		line := slices.IndexFunc(header, func(m metadataField) bool { return m.key == formatKey }) + 1
+		return fmt.Errorf("%s:%d: %w", path, line, err)
+	}
+	lineNumber := bytes.Count(data[:headerEnd], []byte{'\n'})
+	seen := map[string]int{}
+	current, currentLine := "", 0
+	inMetadata, hasIDs := false, false
+
+	lines(data[headerEnd:], func(line []byte, _, _ int) bool {
+		lineNumber++
+
+		if current == "" {
+			if len(bytes.TrimSpace(line)) == 0 {
+				return true
+			}
+			// malformed ids are reported, but only valid ones make it a snapshot file
+			if len(line) < 2 || line[0] != '[' || line[len(line)-1] != ']' {
+				report(lineNumber, "unexpected content outside of a snapshot")
+				return true
+			}
+			if _, ok := getTestID(line); !ok {
+				report(lineNumber, "invalid snapshot id %s", line)
+			} else {
+				hasIDs = true
+			}
+			if first, ok := seen[string(line)]; ok {
+				report(lineNumber, "duplicate snapshot id %s, first at line %d", line, first)
+			} else {
+				seen[string(line)] = lineNumber
+			}
+
+			current, currentLine = string(line), lineNumber
+			inMetadata = format != legacyFormat
+			return true
+		}
+
+		if inMetadata && bytes.HasPrefix(line, []byte(metadataPrefix)) {
+			field := parseMetadataLine(line, metadataPrefix)
+			if field.key == blobKey {
+				if _, err := readBlob(path, field.value); err != nil {
+					report(lineNumber, "invalid blob: %s", err)
+				}
+			}
+			return true
+		}
+		inMetadata = false
+
+		if bytes.Equal(line, endSequenceByteSlice) {
+			current = ""
+			return true
+		}
+		// snapshot ids inside snapshots are escaped, except in the legacy format
+		if _, ok := getTestID(line); ok && format != legacyFormat {
+			report(currentLine, "snapshot %s is missing the end sequence %q", current, endSequence)
+			current, currentLine = string(line), lineNumber
+			inMetadata = true
+		}
+
+		return true
+	})
+
+	if !hasIDs {
+		return nil
+	}
+	if current != "" {
+		report(currentLine, "snapshot %s is missing the end sequence %q", current, endSequence)
+	}
+
+	return errors.Join(errs...)
+}
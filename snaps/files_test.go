@@ -0,0 +1,141 @@
+package snaps
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func writeSnapFile(t *testing.T, content string) string {
+	t.Helper()
+	snapPath := filepath.Join(t.TempDir(), "test.snap")
+	test.NoError(t, os.WriteFile(snapPath, []byte(content), 0o644))
+
+	return snapPath
+}
+
+func TestReadSnapshots(t *testing.T) {
+	snapPath := writeSnapFile(t, "\n[TestB - 1]\nb\n---\n\n[TestA - name]\na\n---\n")
+
+	snapshots, err := ReadSnapshots(snapPath)
+
+	test.NoError(t, err)
+	test.Equal(t, []FileSnapshot{{ID: "TestB - 1", Line: 2}, {ID: "TestA - name", Line: 6}}, snapshots)
+}
+
+func TestSortFile(t *testing.T) {
+	t.Run("should sort snapshots", func(t *testing.T) {
+		snapPath := writeSnapFile(t, "# format: 2\n\n[TestA - 10]\n10\n---\n\n[TestA - 2]\n#@ serializer: go\n2\n---\n")
+
+		test.NoError(t, SortFile(snapPath))
+
+		test.Equal(
+			t,
+			"# format: 2\n\n[TestA - 2]\n#@ serializer: go\n2\n---\n\n[TestA - 10]\n10\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
+	t.Run("should not change standalone snapshots", func(t *testing.T) {
+		snapPath := writeSnapFile(t, "standalone\nvalue")
+
+		test.NoError(t, SortFile(snapPath))
+
+		test.Equal(t, "standalone\nvalue", test.GetFileContent(t, snapPath))
+	})
+}
+
+func TestPruneFile(t *testing.T) {
+	snapPath := writeSnapFile(
+		t,
+		"\n[TestOld - 1]\nold\n---\n\n[TestKept - 1]\nkept\n---\n\n[TestOld/sub - 1]\nsub\n---\n",
+	)
+
+	removed, err := PruneFile(snapPath, regexp.MustCompile("^TestOld"))
+
+	test.NoError(t, err)
+	test.Equal(t, []string{"TestOld - 1", "TestOld/sub - 1"}, removed)
+	test.Equal(t, "\n[TestKept - 1]\nkept\n---\n", test.GetFileContent(t, snapPath))
+
+	t.Run("should not remove frozen snapshots", func(t *testing.T) {
+		content := "# format: 2\n\n[TestOld - 1]\n#@ frozen: true\nold\n---\n\n[TestOld - 2]\nold\n---\n"
+		snapPath := writeSnapFile(t, content)
+
+		removed, err := PruneFile(snapPath, regexp.MustCompile("^TestOld"))
+
+		test.NoError(t, err)
+		test.Equal(t, []string{"TestOld - 2"}, removed)
+		test.Equal(t, "# format: 2\n\n[TestOld - 1]\n#@ frozen: true\nold\n---\n", test.GetFileContent(t, snapPath))
+	})
+}
+
+func TestCheckFile(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:    "valid file",
+			content: "# format: 2\n\n[TestA - 1]\n#@ serializer: go\n\\---\n---\n\n[TestA - name]\n---\n",
+		},
+		{
+			name:    "standalone file",
+			content: "some\nvalue",
+		},
+		{
+			name:     "content outside snapshots",
+			content:  "\n[TestA - 1]\na\n---\nstray\n",
+			expected: []string{":5: unexpected content outside of a snapshot"},
+		},
+		{
+			name:     "invalid id",
+			content:  "\n[TestA]\na\n---\n\n[TestB - 1]\nb\n---\n",
+			expected: []string{":2: invalid snapshot id [TestA]"},
+		},
+		{
+			name:     "duplicate id",
+			content:  "\n[TestA - 1]\na\n---\n\n[TestA - 1]\nb\n---\n",
+			expected: []string{":6: duplicate snapshot id [TestA - 1], first at line 2"},
+		},
+		{
+			name:    "missing end sequence",
+			content: "# format: 2\n\n[TestA - 1]\na\n[TestA - 2]\nb\n",
+			expected: []string{
+				`:3: snapshot [TestA - 1] is missing the end sequence "---"`,
+				`:5: snapshot [TestA - 2] is missing the end sequence "---"`,
+			},
+		},
+		{
+			name:     "missing blob",
+			content:  "# format: 2\n\n[TestA - 1]\n#@ blob: blobs/" + strings.Repeat("a", 64) + ".snap\n\n---\n",
+			expected: []string{":4: invalid blob"},
+		},
+		{
+			name:     "unsupported format",
+			content:  "# go-snaps: v1.0.0\n# format: 3\n\n[TestA - 1]\na\n---\n",
+			expected: []string{`:2: unsupported snapshot format "3"`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			snapPath := writeSnapFile(t, tc.content)
+
+			err := CheckFile(snapPath)
+
+			if len(tc.expected) == 0 {
+				test.NoError(t, err)
+				return
+			}
+
+			errs := strings.Split(err.Error(), "\n")
+			test.Equal(t, len(tc.expected), len(errs))
+			for i, expected := range tc.expected {
+				test.Contains(t, errs[i], snapPath+expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package snaps
+
+import (
+	"regexp"
+	"strings"
+)
+
+/*
+testFilter matches test names the same way `go test` matches them with the -run and -skip flags.
+
+Patterns are split by unbracketed slashes into one regex per level of the test name,
+and by unbracketed '|' into alternatives, so
+
+	-run 'TestA/case1'
+
+runs TestA, as a partial match, and its subtest case1 but no other subtest of TestA, and
+
+	-skip 'TestA/case1'
+
+skips only the subtest case1, and its own subtests, of TestA.
+
+The zero value matches every test.
+*/
+type testFilter struct {
+	run  filterMatch
+	skip filterMatch
+}
+
+// filterMatch is a split -run or -skip pattern.
+type filterMatch interface {
+	// matches reports whether the levels of the test name match, and if they
+	// matched only partially as the pattern has more levels than the name.
+	matches(name []string) (ok, partial bool)
+}
+
+// simpleMatch matches each level of the test name with a regex, nil regexes are invalid and match nothing.
+type simpleMatch []*regexp.Regexp
+
+// alternationMatch matches if any of the alternatives matches.
+type alternationMatch []filterMatch
+
+func newTestFilter(run, skip string) testFilter {
+	var f testFilter
+	if run != "" {
+		f.run = splitPattern(run)
+	}
+	if skip != "" {
+		f.skip = splitPattern(skip)
+	}
+
+	return f
+}
+
+func (m simpleMatch) matches(name []string) (ok, partial bool) {
+	for i, s := range name {
+		if i >= len(m) {
+			break
+		}
+		if m[i] == nil || !m[i].MatchString(s) {
+			return false, false
+		}
+	}
+
+	return true, len(name) < len(m)
+}
+
+func (m alternationMatch) matches(name []string) (ok, partial bool) {
+	for _, alt := range m {
+		if ok, partial = alt.matches(name); ok {
+			return ok, partial
+		}
+	}
+
+	return false, false
+}
+
+// splitPattern splits the pattern the same way the testing package does.
+func splitPattern(s string) filterMatch {
+	var (
+		levels       []string
+		alternatives alternationMatch
+		// nesting of brackets and parentheses
+		brackets, parens int
+	)
+
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '[':
+			brackets++
+		case ']':
+			if brackets--; brackets < 0 {
+				brackets = 0
+			}
+		case '(':
+			if brackets == 0 {
+				parens++
+			}
+		case ')':
+			if brackets == 0 {
+				parens--
+			}
+		case '\\':
+			i++
+		case '/', '|':
+			if brackets == 0 && parens == 0 {
+				levels = append(levels, s[:i])
+				if s[i] == '|' {
+					alternatives = append(alternatives, compileLevels(levels))
+					levels = nil
+				}
+				s = s[i+1:]
+				i = 0
+				continue
+			}
+		}
+		i++
+	}
+	levels = append(levels, s)
+
+	if len(alternatives) == 0 {
+		return compileLevels(levels)
+	}
+
+	return append(alternatives, compileLevels(levels))
+}
+
+func compileLevels(levels []string) simpleMatch {
+	m := make(simpleMatch, len(levels))
+	for i, level := range levels {
+		// invalid patterns fail `go test` before running, this is for direct callers
+		m[i], _ = regexp.Compile(level)
+	}
+
+	return m
+}
+
+// runs reports whether `go test` runs the test, either fully or partially
+// as some of its subtests might be filtered out.
+func (f testFilter) runs(testName string) bool {
+	name := strings.Split(testName, "/")
+
+	if f.run != nil {
+		if ok, _ := f.run.matches(name); !ok {
+			return false
+		}
+	}
+
+	if f.skip != nil {
+		// partial skip matches are ignored, as the subtests matching fully are the skipped ones
+		if skip, partial := f.skip.matches(name); skip && !partial {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f testFilter) empty() bool {
+	return f.run == nil && f.skip == nil
+}
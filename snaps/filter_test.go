@@ -0,0 +1,83 @@
+package snaps
+
+import (
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func TestTestFilter(t *testing.T) {
+	for _, tc := range []struct {
+		run, skip string
+		name      string
+		runs      bool
+	}{
+		{"", "", "TestFoo", true},
+		{"", "", "TestFoo/bar", true},
+		{"TestFoo", "", "TestFoo", true},
+		{"TestFoo", "", "TestFoo/bar", true},
+		{"TestFoo", "", "TestBar", false},
+		{"TestFoo", "", "TestFooBar", true},
+		{"^TestFoo$", "", "TestFooBar", false},
+		{"Foo", "", "TestFoo", true},
+
+		// each level is matched separately
+		{"TestFoo/", "", "TestFoo", true},
+		{"TestFoo/", "", "TestFoo/bar", true},
+		{"TestFoo/bar", "", "TestFoo", true},
+		{"TestFoo/bar", "", "TestFoo/bar", true},
+		{"TestFoo/bar", "", "TestFoo/baz", false},
+		{"TestFoo/bar", "", "TestFoo/bar/baz", true},
+		{"TestFoo/bar", "", "TestBar/bar", false},
+		{"TestFoo/bar/baz", "", "TestFoo/bar", true},
+		{"TestFoo/bar/baz", "", "TestFoo/bar/qux", false},
+		{"/bar", "", "TestFoo/bar", true},
+		{"/bar", "", "TestFoo/baz", false},
+		{"^TestFoo$/^bar$", "", "TestFoo/bar_baz", false},
+
+		// escaped slashes and slashes inside brackets and parentheses don't split levels
+		{"TestFoo[/]bar", "", "TestFoo/bar", false},
+		{"TestFoo(/)bar", "", "TestFoo/bar", false},
+		{`TestFoo\/bar`, "", "TestFoo/bar", false},
+
+		// alternations
+		{"TestFoo|TestBar", "", "TestBar", true},
+		{"TestFoo|TestBar", "", "TestBaz", false},
+		{"TestFoo/bar|TestBar/baz", "", "TestFoo/bar", true},
+		{"TestFoo/bar|TestBar/baz", "", "TestBar/baz", true},
+		{"TestFoo/bar|TestBar/baz", "", "TestFoo/baz", false},
+		{"TestFoo/(bar|baz)", "", "TestFoo/baz", true},
+		{"TestFoo/[|]", "", "TestFoo/|", true},
+
+		// skips
+		{"", "TestFoo", "TestFoo", false},
+		{"", "TestFoo", "TestFoo/bar", false},
+		{"", "TestFoo", "TestBar", true},
+		{"", "TestFoo/bar", "TestFoo", true},
+		{"", "TestFoo/bar", "TestFoo/bar", false},
+		{"", "TestFoo/bar", "TestFoo/bar/baz", false},
+		{"", "TestFoo/bar", "TestFoo/baz", true},
+		{"", "TestFoo|TestBar", "TestBar", false},
+		{"TestFoo", "TestFoo/bar", "TestFoo/baz", true},
+		{"TestFoo", "TestFoo/bar", "TestFoo/bar", false},
+		{"TestFoo/bar", "TestFoo", "TestFoo/bar", false},
+
+		// invalid patterns match nothing
+		{"TestFoo(", "", "TestFoo", false},
+		{"", "TestFoo(", "TestFoo", true},
+	} {
+		t.Run("run="+tc.run+" skip="+tc.skip+" "+tc.name, func(t *testing.T) {
+			test.Equal(
+				t,
+				tc.runs,
+				newTestFilter(tc.run, tc.skip).runs(tc.name),
+			)
+		})
+	}
+
+	t.Run("should be empty without patterns", func(t *testing.T) {
+		test.True(t, newTestFilter("", "").empty())
+		test.False(t, newTestFilter("TestFoo", "").empty())
+		test.False(t, newTestFilter("", "TestFoo").empty())
+	})
+}
@@ -0,0 +1,124 @@
+package snaps
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/gkampitakis/go-snaps/internal/colors"
+)
+
+/*
+go-snaps registers test flags for its settings, taking precedence over the env variables
+
+	go test ./api/... -snaps.update=new -snaps.color=never -snaps.diff-context=5
+
+Flags are only defined in test binaries importing go-snaps, other packages fail with
+"flag provided but not defined", so target the packages using snapshots or use the env variables.
+*/
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+var (
+	// colorMode is the -snaps.color flag value
+	colorMode = colorAuto
+	// autoNoColor is the NO_COLOR, TERM and CI detection used with -snaps.color=auto
+	autoNoColor = colors.NOCOLOR
+	// diffContext is the number of unchanged lines shown around changes in diffs
+	diffContext = 3
+)
+
+func init() {
+	flag.Func(
+		"snaps.update",
+		"set the update `policy` (never, new, update, clean, always, pending or a test name pattern), overriding UPDATE_SNAPS",
+		setUpdateFlag,
+	)
+	flag.Func(
+		"snaps.color",
+		"color output `mode`: auto, always or never, overriding NO_COLOR (default auto)",
+		setColorFlag,
+	)
+	flag.Func(
+		"snaps.dry-run",
+		"report the changes to snapshots without making them, in `format` text or json, overriding SNAPS_DRY_RUN",
+		setDryRunFlag,
+	)
+	flag.StringVar(
+		&dryRunOutput,
+		"snaps.dry-run-output",
+		dryRunOutput,
+		"write the dry run report to `file` instead of stdout, overriding SNAPS_DRY_RUN_OUTPUT",
+	)
+	flag.StringVar(
+		&artifactDir,
+		"snaps.artifact-dir",
+		artifactDir,
+		"write the received snapshots of failing comparisons to `dir`, overriding SNAPS_ARTIFACT_DIR",
+	)
+	flag.Func(
+		"snaps.diff-context",
+		"number of unchanged `lines` shown around changes in snapshot diffs (default 3)",
+		setDiffContextFlag,
+	)
+}
+
+func setUpdateFlag(s string) error {
+	if err := checkUpdate(s); err != nil {
+		return err
+	}
+
+	updateVAR = s
+	return nil
+}
+
+func setColorFlag(s string) error {
+	switch s {
+	case colorAuto:
+		colors.NOCOLOR = autoNoColor
+	case colorAlways:
+		colors.NOCOLOR = false
+	case colorNever:
+		colors.NOCOLOR = true
+	default:
+		return fmt.Errorf("invalid color %q, must be auto, always or never", s)
+	}
+
+	colorMode = s
+	return nil
+}
+
+func setDiffContextFlag(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid diff context %q, must be a non-negative number", s)
+	}
+
+	diffContext = n
+	return nil
+}
+
+func setDryRunFlag(s string) error {
+	if s != dryRunText && s != dryRunJSON {
+		return fmt.Errorf("invalid dry run format %q, must be text or json", s)
+	}
+
+	dryRunFormat = s
+	return nil
+}
+
+// settings describes the settings in effect for the summary.
+func settings() string {
+	s := fmt.Sprintf("update=%s color=%s diff-context=%d", policyName(), colorMode, diffContext)
+	if isDryRun() {
+		s += " dry-run=" + dryRunFormat
+	}
+	if artifactDir != "" {
+		s += " artifact-dir=" + artifactDir
+	}
+
+	return s
+}
@@ -0,0 +1,77 @@
+package snaps
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/colors"
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func TestFlags(t *testing.T) {
+	set := func(t *testing.T, name, value string) error {
+		t.Helper()
+		f := flag.Lookup(name)
+		test.True(t, f != nil)
+
+		return f.Value.Set(value)
+	}
+
+	t.Run("snaps.update should override UPDATE_SNAPS", func(t *testing.T) {
+		setupSnapshot(t, "flags_test.snap", false, "true")
+
+		test.NoError(t, set(t, "snaps.update", "new"))
+		test.Equal(t, PolicyNew, updatePolicy(""))
+		test.Contains(t, settings(), "update=new ")
+	})
+
+	t.Run("snaps.update should reject invalid values", func(t *testing.T) {
+		setupSnapshot(t, "flags_test.snap", false, "true")
+
+		test.Contains(t, set(t, "snaps.update", "updte").Error(), `did you mean "update"?`)
+		test.Contains(t, set(t, "snaps.update", "Test[").Error(), "invalid update pattern")
+		test.Equal(t, "true", updateVAR)
+	})
+
+	t.Run("snaps.color", func(t *testing.T) {
+		noColor, mode := colors.NOCOLOR, colorMode
+		t.Cleanup(func() {
+			colors.NOCOLOR = noColor
+			colorMode = mode
+		})
+
+		test.NoError(t, set(t, "snaps.color", "never"))
+		test.True(t, colors.NOCOLOR)
+
+		test.NoError(t, set(t, "snaps.color", "always"))
+		test.False(t, colors.NOCOLOR)
+		test.Equal(t, colorAlways, colorMode)
+
+		test.NoError(t, set(t, "snaps.color", "auto"))
+		test.Equal(t, autoNoColor, colors.NOCOLOR)
+
+		test.Equal(t, "invalid color \"blue\", must be auto, always or never",
+			set(t, "snaps.color", "blue").Error())
+	})
+
+	t.Run("snaps.diff-context", func(t *testing.T) {
+		noColor, context := colors.NOCOLOR, diffContext
+		t.Cleanup(func() {
+			colors.NOCOLOR = noColor
+			diffContext = context
+		})
+		colors.NOCOLOR = true
+
+		test.NoError(t, set(t, "snaps.diff-context", "0"))
+		test.Equal(t, 0, diffContext)
+		test.Equal(
+			t,
+			"\n- Snapshot - 1\n+ Received + 1\n\n- b\n+ c\n\n",
+			prettyDiff("a\nb\nd\n", "a\nc\nd\n", "", -1),
+		)
+
+		test.Equal(t, "invalid diff context \"-1\", must be a non-negative number",
+			set(t, "snaps.diff-context", "-1").Error())
+		test.Equal(t, 0, diffContext)
+	})
+}
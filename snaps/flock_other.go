@@ -0,0 +1,8 @@
+//go:build !unix
+
+package snaps
+
+// flock is a no-op on platforms without flock, snapshot files are only locked inside the process.
+func flock(string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package snaps
+
+import (
+	"os"
+	"syscall"
+)
+
+// flock acquires an exclusive advisory lock on the lock file at path, blocking until it's available.
+//
+// The lock file is removed on unlock, so it's locked again if it was removed or replaced
+// while waiting for the lock.
+func flock(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		locked, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if current, err := os.Stat(path); err != nil || !os.SameFile(locked, current) {
+			f.Close()
+			continue
+		}
+
+		return func() {
+			os.Remove(path)
+			syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+			f.Close()
+		}, nil
+	}
+}
@@ -0,0 +1,51 @@
+package snaps
+
+import (
+	"sync/atomic"
+)
+
+// pendingFlushes counts the running tests with deferred writes, when none is running anymore
+// the buffered writes are flushed.
+var pendingFlushes atomic.Int64
+
+/*
+Flush writes the snapshot updates buffered with snaps.DeferWrites.
+
+snaps.Clean calls it, so you only need it if you are not using snaps.Clean in TestMain.
+
+	func TestMain(m *testing.M) {
+		v := m.Run()
+
+		if err := snaps.Flush(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		os.Exit(v)
+	}
+
+As a fallback buffered writes are flushed whenever no test using snaps.DeferWrites is running anymore.
+Tests that don't run in parallel finish one after the other, so without snaps.Clean or snaps.Flush
+the snapshot file is written after each of them.
+*/
+func Flush() error {
+	return snapshotFiles.flush()
+}
+
+// deferWrites registers flushing the buffered writes when the test finishes, if writes are deferred.
+func (c *Config) deferWrites(t testingT) {
+	if !c.deferred {
+		return
+	}
+
+	pendingFlushes.Add(1)
+	t.Cleanup(func() {
+		if pendingFlushes.Add(-1) > 0 {
+			return
+		}
+
+		if err := Flush(); err != nil {
+			t.Error(err)
+		}
+	})
+}
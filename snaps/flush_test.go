@@ -0,0 +1,97 @@
+package snaps
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+const flushFilename = "flush_test.snap"
+
+func TestDeferWrites(t *testing.T) {
+	// creates two snapshots and then updates them
+	run := func(t *testing.T, c *Config, cleanups *[]func()) {
+		t.Helper()
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+		mockT.MockCleanup = func(f func()) { *cleanups = append(*cleanups, f) }
+
+		c.MatchSnapshot(mockT, "first")
+		c.MatchJSON(mockT, `{"second":true}`)
+		testsRegistry = newRegistry()
+		c.MatchSnapshot(mockT, "first\nupdated")
+		c.MatchJSON(mockT, `{"second":"updated"}`)
+	}
+
+	t.Run("should write once flushed", func(t *testing.T) {
+		snapPath := setupSnapshot(t, flushFilename, false, "true")
+		cleanups := []func(){}
+
+		run(t, WithConfig(), &cleanups)
+		expected := test.GetFileContent(t, snapPath)
+
+		test.NoError(t, os.Remove(snapPath))
+		testsRegistry = newRegistry()
+		snapshotFiles = newSnapshotIndex()
+		testEvents = newTestEvents()
+
+		run(t, WithConfig(DeferWrites()), &cleanups)
+		t.Cleanup(func() { pendingFlushes.Store(0) })
+
+		_, err := os.Stat(snapPath)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+
+		test.NoError(t, Flush())
+		test.Equal(t, expected, test.GetFileContent(t, snapPath))
+		test.Equal(t, 2, testEvents.items[added])
+		test.Equal(t, 2, testEvents.items[updated])
+	})
+
+	t.Run("should flush when last test finishes", func(t *testing.T) {
+		snapPath := setupSnapshot(t, flushFilename, false, "true")
+		cleanups := []func(){}
+
+		run(t, WithConfig(DeferWrites()), &cleanups)
+
+		test.Equal(t, int64(4), pendingFlushes.Load())
+		for _, cleanup := range cleanups {
+			_, err := os.Stat(snapPath)
+			test.True(t, errors.Is(err, os.ErrNotExist))
+
+			cleanup()
+		}
+
+		test.Equal(t, int64(0), pendingFlushes.Load())
+		test.Equal(
+			t,
+			"\n[mock-name - 1]\nfirst\nupdated\n---\n\n[mock-name - 2]\n{\n \"second\": \"updated\"\n}\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+	t.Run("should write snapshots without deferred writes right away", func(t *testing.T) {
+		snapPath := setupSnapshot(t, flushFilename, false)
+		cleanups := []func(){}
+		t.Cleanup(func() { pendingFlushes.Store(0) })
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+		mockT.MockCleanup = func(f func()) { cleanups = append(cleanups, f) }
+
+		WithConfig(DeferWrites()).MatchSnapshot(mockT, "deferred")
+		_, err := os.Stat(snapPath)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+
+		// another test matching the same snapshot file without snaps.DeferWrites
+		mockT.MockName = func() string { return "mock-other" }
+		MatchSnapshot(mockT, "not deferred")
+
+		test.Equal(
+			t,
+			"\n[mock-name - 1]\ndeferred\n---\n\n[mock-other - 1]\nnot deferred\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+}
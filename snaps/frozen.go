@@ -0,0 +1,60 @@
+package snaps
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gkampitakis/go-snaps/internal/colors"
+)
+
+/*
+Frozen snapshots are marked in the snapshot file with a metadata line
+
+	[TestPublicAPI - 1]
+	#@ frozen: true
+	<snapshot>
+	---
+
+and are never updated, by any update policy or pending review, or removed by snaps.Clean or snaps.PruneFile.
+
+Standalone and inline snapshots have no metadata, so they are only frozen by snaps.Frozen.
+*/
+const frozenKey = "frozen"
+
+var (
+	errFrozen = errors.New("frozen snapshot, it can't be updated")
+	frozenMsg = colors.Sprint(colors.Red, errorSymbol+"Snapshot is frozen and can't be updated")
+)
+
+func isFrozen(m metadata) bool {
+	return m.get(frozenKey) == "true"
+}
+
+// isFrozenSnapshot reports whether the snapshot under testID is frozen, either by snaps.Frozen
+// or by the marker in the snapshot file.
+func (c *Config) isFrozenSnapshot(testID, snapPath string) bool {
+	if c.frozen {
+		return true
+	}
+
+	f, err := snapshotFiles.load(snapPath)
+	if err != nil {
+		return false
+	}
+
+	f.RLock()
+	defer f.RUnlock()
+	e, ok := f.entries[testID]
+
+	return ok && isFrozen(f.metadata(e))
+}
+
+// hasFrozen reports whether the snapshot file contains frozen snapshots.
+func hasFrozen(data []byte) bool {
+	f, err := parseSnapshotFile(data)
+	if err != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(f.order, func(e *indexEntry) bool { return isFrozen(f.metadata(e)) })
+}
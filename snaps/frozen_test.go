@@ -0,0 +1,102 @@
+package snaps
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+const frozenFilename = "frozen_test.snap"
+
+func TestFrozen(t *testing.T) {
+	frozenSnap := "# format: 2\n# go-snaps: " + snapsVersion() + "\n\n[mock-name - 1]\n#@ frozen: true\nhello world\n---\n"
+
+	t.Run("should store new snapshots with the frozen marker", func(t *testing.T) {
+		snapPath := setupSnapshot(t, frozenFilename, false)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+		WithConfig(Frozen()).MatchSnapshot(mockT, "hello world")
+
+		test.Equal(t, frozenSnap, test.GetFileContent(t, snapPath))
+	})
+
+	t.Run("should fail on mismatch even with UPDATE_SNAPS=always", func(t *testing.T) {
+		snapPath := setupSnapshot(t, frozenFilename, false, "always")
+		test.NoError(t, os.WriteFile(snapPath, []byte(frozenSnap), 0o644))
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) {
+			test.Contains(t, args[0].(string), frozenMsg)
+		}
+		// frozen by the marker in the file
+		MatchSnapshot(mockT, "bye world")
+
+		test.Equal(t, frozenSnap, test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+
+	t.Run("should only freeze standalone snapshots with the option", func(t *testing.T) {
+		snapPath := setupSnapshot(t, "TestFrozen_should_only_freeze_standalone_snapshots_with_the_option_1.snap", false, "true")
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockName = func() string { return t.Name() }
+		mockT.MockLog = func(...any) {}
+		mockT.MockError = func(args ...any) {
+			test.Contains(t, args[0].(string), frozenMsg)
+		}
+		WithConfig(Frozen()).MatchStandaloneSnapshot(mockT, "hello world")
+		// standalone snapshots have no metadata to store the marker in
+		test.Equal(t, "hello world", test.GetFileContent(t, snapPath))
+
+		standaloneTestsRegistry = newStandaloneRegistry()
+		WithConfig(Frozen()).MatchStandaloneSnapshot(mockT, "bye world")
+		test.Equal(t, "hello world", test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[erred])
+
+		standaloneTestsRegistry = newStandaloneRegistry()
+		MatchStandaloneSnapshot(mockT, "bye world")
+		test.Equal(t, "bye world", test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[updated])
+	})
+
+	t.Run("should refuse to update frozen snapshots", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), frozenFilename)
+		test.NoError(t, os.WriteFile(snapPath, []byte(frozenSnap), 0o644))
+		t.Cleanup(func() { snapshotFiles = newSnapshotIndex() })
+
+		err := updateSnapshot("[mock-name - 1]", "bye world", snapPath, nil, false)
+
+		test.True(t, errors.Is(err, errFrozen))
+		test.Equal(t, frozenSnap, test.GetFileContent(t, snapPath))
+	})
+
+	t.Run("should never remove frozen snapshots", func(t *testing.T) {
+		dir := t.TempDir()
+		snapPath := filepath.Join(dir, "used.snap")
+		obsoletePath := filepath.Join(dir, "obsolete.snap")
+		content := "# format: 2\n\n[TestOld - 1]\n#@ frozen: true\nold\n---\n\n[TestRemoved - 1]\nremoved\n---\n"
+		test.NoError(t, os.WriteFile(snapPath, []byte(content), 0o644))
+		test.NoError(t, os.WriteFile(obsoletePath, []byte(content), 0o644))
+		tests := map[string]map[string]int{snapPath: {"TestUsed": 1}}
+
+		obsoleteFiles, used, _ := examineFiles(tests, set{}, testFilter{}, true)
+		test.Equal(t, 0, len(obsoleteFiles))
+		test.Equal(t, 2, len(used))
+
+		obsolete, _, err := examineSnaps(tests, nil, used, testFilter{}, 1, true, false, nil)
+
+		test.NoError(t, err)
+		test.Equal(t, []string{"TestRemoved - 1", "TestRemoved - 1"}, obsolete)
+		for _, path := range used {
+			test.Equal(
+				t,
+				"# format: 2\n\n[TestOld - 1]\n#@ frozen: true\nold\n---\n",
+				test.GetFileContent(t, path),
+			)
+		}
+	})
+}
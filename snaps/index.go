@@ -0,0 +1,285 @@
+package snaps
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"sync"
+)
+
+var snapshotFiles = newSnapshotIndex()
+
+// syncSnapshotIndex keeps the parsed snapshot files of the process, loaded on first use.
+//
+// All reads and writes of snapshots inside snapshot files go through it, so it is kept in sync
+// with the storage.
+type syncSnapshotIndex struct {
+	files map[string]*snapshotFile
+	sync.Mutex
+}
+
+func newSnapshotIndex() *syncSnapshotIndex {
+	return &syncSnapshotIndex{
+		files: make(map[string]*snapshotFile),
+		Mutex: sync.Mutex{},
+	}
+}
+
+// load returns the parsed snapshot file, reading it from its storage if it isn't loaded yet.
+//
+// A missing file is loaded as empty.
+func (s *syncSnapshotIndex) load(snapPath string) (*snapshotFile, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if f, ok := s.files[snapPath]; ok {
+		return f, nil
+	}
+
+	data, err := storageFor(snapPath).Read(snapPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	f, err := parseSnapshotFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, snapPath)
+	}
+	s.files[snapPath] = f
+
+	return f, nil
+}
+
+// flush writes the snapshot files with buffered writes.
+func (s *syncSnapshotIndex) flush() error {
+	s.Lock()
+	files := maps.Clone(s.files)
+	s.Unlock()
+
+	var errs []error
+	for _, snapPath := range slices.Sorted(maps.Keys(files)) {
+		if err := files[snapPath].flush(snapPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// invalidate drops the parsed snapshot file, so it's read again on next use.
+func (s *syncSnapshotIndex) invalidate(snapPath string) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.files, snapPath)
+}
+
+// get returns the snapshot stored under testID and the line of the test id.
+func (f *snapshotFile) get(testID string) (string, int, bool) {
+	f.RLock()
+	defer f.RUnlock()
+
+	e, ok := f.entries[testID]
+	if !ok {
+		return "", -1, false
+	}
+
+	return f.snapshot(e), e.line, true
+}
+
+// reload reads the snapshot file again if it was changed outside of the index, e.g. by another process
+// sharing the snapshot directory. It must be called holding the file lock.
+func (f *snapshotFile) reload(snapPath string) error {
+	data, err := storageFor(snapPath).Read(snapPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// buffered writes are not lost, they override the file when flushed
+	if f.dirty || bytes.Equal(data, f.data) {
+		return nil
+	}
+
+	parsed, err := parseSnapshotFile(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, snapPath)
+	}
+	f.assign(parsed)
+
+	return nil
+}
+
+// add appends a new snapshot to the snapshot file, deferred buffers the write until flushed.
+//
+// If meanwhile the snapshot was added by another process, it is updated instead.
+func (f *snapshotFile) add(snapPath, testID, snapshot string, meta *snapshotMetadata, deferred bool) error {
+	unlock, err := lockFile(snapPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.reload(snapPath); err != nil {
+		return err
+	}
+	if _, exists := f.entries[testID]; exists {
+		return f.replace(snapPath, testID, snapshot, meta, deferred)
+	}
+
+	metaLines, body := f.block(snapshot, meta)
+	header := f.lineEndings([]byte("\n" + testID + "\n"))
+	end := f.lineEndings([]byte(endSequence + "\n"))
+	data := make([]byte, 0, len(f.data)+len(header)+len(metaLines)+len(body)+len(end))
+	data = append(data, f.data...)
+	data = append(data, header...)
+	data = append(data, metaLines...)
+	data = append(data, body...)
+	data = append(data, end...)
+
+	if err := f.write(snapPath, data, deferred); err != nil {
+		return err
+	}
+
+	start := len(f.data) + len(header)
+	e := &indexEntry{
+		id:        testID,
+		line:      bytes.Count(f.data, []byte{'\n'}) + 2,
+		start:     start,
+		bodyStart: start + len(metaLines),
+		end:       start + len(metaLines) + len(body),
+	}
+	f.data = data
+	f.entries[testID] = e
+	f.order = append(f.order, e)
+
+	return nil
+}
+
+// update replaces the snapshot stored under testID and writes the snapshot file,
+// deferred buffers the write until flushed.
+func (f *snapshotFile) update(snapPath, testID, snapshot string, meta *snapshotMetadata, deferred bool) error {
+	unlock, err := lockFile(snapPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.reload(snapPath); err != nil {
+		return err
+	}
+
+	return f.replace(snapPath, testID, snapshot, meta, deferred)
+}
+
+func (f *snapshotFile) replace(snapPath, testID, snapshot string, meta *snapshotMetadata, deferred bool) error {
+	if _, ok := f.entries[testID]; !ok {
+		return errSnapNotFound
+	}
+	if isFrozen(f.metadata(f.entries[testID])) {
+		return errFrozen
+	}
+	// block can upgrade the file, so the entry is looked up after
+	metaLines, body := f.block(snapshot, meta)
+	e := f.entries[testID]
+
+	old := f.data[e.start:e.end]
+	newLen := len(metaLines) + len(body)
+	delta := newLen - len(old)
+	linesDelta := bytes.Count(metaLines, []byte{'\n'}) + bytes.Count(body, []byte{'\n'}) -
+		bytes.Count(old, []byte{'\n'})
+
+	data := make([]byte, 0, len(f.data)+delta)
+	data = append(data, f.data[:e.start]...)
+	data = append(data, metaLines...)
+	data = append(data, body...)
+	data = append(data, f.data[e.end:]...)
+
+	if err := f.write(snapPath, data, deferred); err != nil {
+		return err
+	}
+
+	f.data = data
+	for _, other := range f.order {
+		if other.start > e.start {
+			other.start += delta
+			other.bodyStart += delta
+			other.end += delta
+			other.line += linesDelta
+		}
+	}
+	e.bodyStart = e.start + len(metaLines)
+	e.end = e.start + newLen
+
+	return nil
+}
+
+// upgrade rewrites a legacy format file to the current format with the given header.
+func (f *snapshotFile) upgrade(snapPath string, header metadata) error {
+	unlock, err := lockFile(snapPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.reload(snapPath); err != nil {
+		return err
+	}
+	if f.format != legacyFormat || len(f.data) == 0 {
+		return nil
+	}
+
+	f.migrate(header)
+
+	return f.write(snapPath, f.data, false)
+}
+
+// write writes data to the snapshot file, or buffers it if the write is deferred.
+//
+// Writes that are not deferred also write the buffered changes, as data contains them.
+func (f *snapshotFile) write(snapPath string, data []byte, deferred bool) error {
+	if deferred {
+		f.dirty = true
+		return nil
+	}
+
+	if err := storageFor(snapPath).Write(snapPath, data); err != nil {
+		return err
+	}
+	f.dirty = false
+
+	return nil
+}
+
+func (f *snapshotFile) flush(snapPath string) error {
+	unlock, err := lockFile(snapPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f.Lock()
+	defer f.Unlock()
+
+	if !f.dirty {
+		return nil
+	}
+
+	if err := storageFor(snapPath).Write(snapPath, f.data); err != nil {
+		return err
+	}
+	f.dirty = false
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package snaps
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func TestSnapshotIndex(t *testing.T) {
+	data := "\n[TestA - 1]\nline 1\nline 2\n---\n\n[TestB - 1]\n---\n\n[TestA - 1]\nduplicate\n---\n"
+
+	t.Run("should index snapshot file", func(t *testing.T) {
+		f, err := parseSnapshotFile([]byte(data + "\n[TestC - 1]\nnot closed\n"))
+		test.NoError(t, err)
+
+		snap, line, ok := f.get("[TestA - 1]")
+		test.True(t, ok)
+		test.Equal(t, "line 1\nline 2", snap)
+		test.Equal(t, 2, line)
+
+		snap, line, ok = f.get("[TestB - 1]")
+		test.True(t, ok)
+		test.Equal(t, "", snap)
+		test.Equal(t, 7, line)
+
+		_, _, ok = f.get("[TestC - 1]")
+		test.False(t, ok)
+		_, _, ok = f.get("[TestD - 1]")
+		test.False(t, ok)
+	})
+
+	t.Run("should reject unsupported formats", func(t *testing.T) {
+		for _, format := range []string{"3", "two"} {
+			_, err := parseSnapshotFile([]byte("# format: " + format + "\n\n[TestA - 1]\na\n---\n"))
+			test.True(t, errors.Is(err, errUnsupportedFormat))
+		}
+
+		snapPath := setupSnapshot(t, "index_test.snap", false, "always")
+		content := "# format: 3\n\n[mock-name - 1]\nhello world\n---\n"
+		test.NoError(t, os.WriteFile(snapPath, []byte(content), 0o644))
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) {
+			test.True(t, errors.Is(args[0].(error), errUnsupportedFormat))
+		}
+		MatchSnapshot(mockT, "bye world")
+
+		test.Equal(t, content, test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+
+	t.Run("should keep index in sync with writes", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__", "index.snap")
+		test.NoError(t, os.MkdirAll(filepath.Dir(snapPath), 0o755))
+		test.NoError(t, os.WriteFile(snapPath, []byte(data), 0o644))
+
+		f, err := snapshotFiles.load(snapPath)
+		test.NoError(t, err)
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		test.NoError(t, f.update(snapPath, "[TestA - 1]", "updated", nil, false))
+		test.NoError(t, f.add(snapPath, "[TestD - 1]", "added\nvalue", nil, false))
+		test.NoError(t, f.update(snapPath, "[TestB - 1]", "not empty\nanymore", nil, false))
+
+		// index should match a freshly parsed file
+		b, err := os.ReadFile(snapPath)
+		test.NoError(t, err)
+		parsed, err := parseSnapshotFile(b)
+		test.NoError(t, err)
+
+		for _, id := range []string{"[TestA - 1]", "[TestB - 1]", "[TestD - 1]"} {
+			snap, line, ok := f.get(id)
+			expectedSnap, expectedLine, _ := parsed.get(id)
+
+			test.True(t, ok)
+			test.Equal(t, expectedSnap, snap)
+			test.Equal(t, expectedLine, line)
+		}
+
+		snap, line, _ := f.get("[TestD - 1]")
+		test.Equal(t, "added\nvalue", snap)
+		test.Equal(t, 15, line)
+		test.Equal(t, 1, strings.Count(string(b), "updated"))
+	})
+}
+
+func TestSnapshotIndexCRLF(t *testing.T) {
+	snapPath := filepath.Join(t.TempDir(), "__snapshots__", "crlf.snap")
+	test.NoError(t, os.MkdirAll(filepath.Dir(snapPath), 0o755))
+	test.NoError(t, os.WriteFile(snapPath, []byte("\r\n[TestA - 1]\r\nline 1\r\nline 2\r\n---\r\n"), 0o644))
+	t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+	snap, line, err := getPrevSnapshot("[TestA - 1]", snapPath)
+	test.NoError(t, err)
+	test.Equal(t, "line 1\nline 2", snap)
+	test.Equal(t, 2, line)
+
+	test.NoError(t, updateSnapshot("[TestA - 1]", "updated", snapPath, nil, false))
+	test.NoError(t, addNewSnapshot("[TestB - 1]", "added\nvalue", snapPath, nil, false))
+
+	snap, line, err = getPrevSnapshot("[TestB - 1]", snapPath)
+	test.NoError(t, err)
+	test.Equal(t, "added\nvalue", snap)
+	test.Equal(t, 6, line)
+	test.Equal(
+		t,
+		"\r\n[TestA - 1]\r\nupdated\r\n---\r\n\r\n[TestB - 1]\r\nadded\r\nvalue\r\n---\r\n",
+		test.GetFileContent(t, snapPath),
+	)
+}
+
+func BenchmarkGetPrevSnapshot(b *testing.B) {
+	const entries = 5000
+	snapPath := filepath.Join(b.TempDir(), "__snapshots__", "bench.snap")
+
+	var data strings.Builder
+	for i := 0; i < entries; i++ {
+		fmt.Fprintf(&data, "\n[TestBenchmark/case_%d - 1]\nsnapshot value %d\nspanning lines\n---\n", i, i)
+	}
+	if err := os.MkdirAll(filepath.Dir(snapPath), 0o755); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(snapPath, []byte(data.String()), 0o644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("indexed", func(b *testing.B) {
+		snapshotFiles.invalidate(snapPath)
+
+		for i := 0; i < b.N; i++ {
+			if _, _, err := getPrevSnapshot(fmt.Sprintf("[TestBenchmark/case_%d - 1]", i%entries), snapPath); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// the lookup before the index, reading and scanning the file on every call
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, _, err := scanPrevSnapshot(fmt.Sprintf("[TestBenchmark/case_%d - 1]", i%entries), snapPath); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	snapshotFiles.invalidate(snapPath)
+}
+
+// scanPrevSnapshot is the linear lookup getPrevSnapshot used before snapshot files were indexed.
+func scanPrevSnapshot(testID, snapPath string) (string, int, error) {
+	f, err := os.ReadFile(snapPath)
+	if err != nil {
+		return "", -1, errSnapNotFound
+	}
+
+	lineNumber := 1
+	tid := []byte(testID)
+
+	s := bufio.NewScanner(bytes.NewReader(f))
+	s.Buffer([]byte{}, math.MaxInt)
+	for s.Scan() {
+		l := s.Bytes()
+		if !bytes.Equal(l, tid) {
+			lineNumber++
+			continue
+		}
+		var snapshot strings.Builder
+
+		for s.Scan() {
+			line := s.Bytes()
+
+			if bytes.Equal(line, []byte(endSequence)) {
+				return strings.TrimSuffix(snapshot.String(), "\n"), lineNumber, nil
+			}
+			snapshot.Write(line)
+			snapshot.WriteByte('\n')
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return "", -1, err
+	}
+
+	return "", -1, errSnapNotFound
+}
@@ -0,0 +1,75 @@
+package snaps
+
+import (
+	"strings"
+	"sync"
+)
+
+// lockExt is the extension of the lock files, which only exist while a snapshot file is locked
+const lockExt = ".lock"
+
+var fileLocks = newFileLocks()
+
+// locker can optionally be implemented by a Storage for locking a snapshot file
+// across processes.
+type locker interface {
+	Lock(path string) (unlock func(), err error)
+}
+
+// syncFileLocks holds a lock per snapshot file, so writes to different files don't block each other.
+type syncFileLocks struct {
+	locks map[string]*sync.Mutex
+	sync.Mutex
+}
+
+func newFileLocks() *syncFileLocks {
+	return &syncFileLocks{
+		locks: make(map[string]*sync.Mutex),
+		Mutex: sync.Mutex{},
+	}
+}
+
+func (s *syncFileLocks) get(snapPath string) *sync.Mutex {
+	s.Lock()
+	defer s.Unlock()
+
+	m, ok := s.locks[snapPath]
+	if !ok {
+		m = &sync.Mutex{}
+		s.locks[snapPath] = m
+	}
+
+	return m
+}
+
+// lockFile locks the snapshot file for writing inside the process and, if its storage supports it,
+// across processes.
+func lockFile(snapPath string) (func(), error) {
+	m := fileLocks.get(snapPath)
+	m.Lock()
+
+	l, ok := storageFor(snapPath).(locker)
+	if !ok {
+		return m.Unlock, nil
+	}
+
+	unlock, err := l.Lock(snapPath)
+	if err != nil {
+		m.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		unlock()
+		m.Unlock()
+	}, nil
+}
+
+// lockPath returns the path of the lock file for path, next to it so it's removed along with the lock.
+func lockPath(path string) string {
+	return path + lockExt
+}
+
+func isLockFile(path string) bool {
+	return strings.HasSuffix(path, lockExt)
+}
@@ -0,0 +1,142 @@
+package snaps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func TestOSStorageWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "__snapshots__")
+	snapPath := filepath.Join(dir, "atomic.snap")
+
+	test.NoError(t, defaultStorage.Write(snapPath, []byte("first")))
+	test.NoError(t, defaultStorage.Write(snapPath, []byte("second")))
+
+	test.Equal(t, "second", test.GetFileContent(t, snapPath))
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	test.NoError(t, err)
+	test.Equal(t, 1, len(entries))
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	// new files are created with the mode os.WriteFile creates them, respecting the umask
+	expectedPath := filepath.Join(t.TempDir(), "expected")
+	test.NoError(t, os.WriteFile(expectedPath, nil, 0o644))
+	expected, err := os.Stat(expectedPath)
+	test.NoError(t, err)
+	info, err := os.Stat(snapPath)
+	test.NoError(t, err)
+	test.Equal(t, expected.Mode().Perm(), info.Mode().Perm())
+
+	// existing files keep their mode
+	test.NoError(t, os.Chmod(snapPath, 0o640))
+	test.NoError(t, defaultStorage.Write(snapPath, []byte("third")))
+	info, err = os.Stat(snapPath)
+	test.NoError(t, err)
+	test.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
+
+func TestLockFile(t *testing.T) {
+	t.Run("should serialize writes to the same file", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__", "concurrent.snap")
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				test.NoError(t, addNewSnapshot(fmt.Sprintf("[TestConcurrent - %d]", i), "value", snapPath, nil, false))
+			}()
+		}
+		wg.Wait()
+
+		b, err := os.ReadFile(snapPath)
+		test.NoError(t, err)
+		f, err := parseSnapshotFile(b)
+		test.NoError(t, err)
+		test.Equal(t, 50, len(f.entries))
+	})
+
+	t.Run("should reload file changed by another process", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__", "shared.snap")
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		test.NoError(t, addNewSnapshot("[TestA - 1]", "a", snapPath, nil, false))
+
+		// emulate another process appending to the file
+		f, err := os.OpenFile(snapPath, os.O_APPEND|os.O_WRONLY, 0o644)
+		test.NoError(t, err)
+		_, err = f.WriteString("\n[TestB - 1]\nb\n---\n")
+		test.NoError(t, err)
+		f.Close()
+
+		test.NoError(t, addNewSnapshot("[TestB - 1]", "b updated", snapPath, nil, false))
+		test.NoError(t, updateSnapshot("[TestA - 1]", "a updated", snapPath, nil, false))
+
+		test.Equal(
+			t,
+			"\n[TestA - 1]\na updated\n---\n\n[TestB - 1]\nb updated\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
+	t.Run("should lock across processes", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("flock is not supported")
+		}
+
+		path := lockPath(filepath.Join(t.TempDir(), "cross-process.snap"))
+		t.Cleanup(func() { os.Remove(path) })
+
+		unlock, err := flock(path)
+		test.NoError(t, err)
+
+		acquired := make(chan struct{})
+		go func() {
+			unlock, err := flock(path)
+			test.NoError(t, err)
+			close(acquired)
+			unlock()
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("lock acquired while held")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		unlock()
+		<-acquired
+
+		_, err = os.Stat(path)
+		test.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("should remove the lock file of the snapshot file on unlock", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__", "lock_test.snap")
+
+		unlock, err := lockFile(snapPath)
+		test.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			_, err = os.Stat(snapPath + lockExt)
+			test.NoError(t, err)
+		}
+		unlock()
+
+		entries, err := os.ReadDir(filepath.Dir(snapPath))
+		test.NoError(t, err)
+		test.Equal(t, 0, len(entries))
+	})
+}
@@ -7,12 +7,10 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
-	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
-
-	"github.com/kr/pretty"
 )
 
 type inlineSnapshotsLineMapping struct {
@@ -52,6 +50,14 @@ func (i *inlineSnapshotsLineMapping) GetLine(file string, index int) int {
 	return line
 }
 
+// GetIndex returns the index of the call at line, or -1 if there is no call registered at line.
+func (i *inlineSnapshotsLineMapping) GetIndex(file string, line int) int {
+	i.RLock()
+	defer i.RUnlock()
+
+	return slices.Index(i.mapping[file], line)
+}
+
 type inlineSnapshot *string
 
 var (
@@ -115,7 +121,8 @@ func matchInlineSnapshot(c *Config, t testingT, received any, inlineSnap inlineS
 	filename, line := baseCaller(1)
 
 	// we should only register call positions if we are modifying the file and the file hasn't been registered yet.
-	if (inlineSnap == nil || shouldUpdate(c.update)) &&
+	// Pending snapshots and artifacts also need them, as they are stored by call index.
+	if (inlineSnap == nil || shouldUpdate(c.update, t.Name()) || shouldPend(c.update) || artifactDir != "") &&
 		inlineSnapshotLineMapping.AddFileIfNotExists(filename) {
 		if err := registerInlineCallIdx(filename); err != nil {
 			handleError(t, err)
@@ -123,19 +130,9 @@ func matchInlineSnapshot(c *Config, t testingT, received any, inlineSnap inlineS
 		}
 	}
 
+	change := c.inlineChange(t.Name(), filename, line, snapshot)
 	if inlineSnap == nil {
-		if !shouldCreate(c.update) {
-			handleError(t, errSnapNotFound)
-			return
-		}
-
-		if err := upsertInlineSnapshot(filename, line, snapshot); err != nil {
-			handleError(t, err)
-			return
-		}
-
-		t.Log(addedMsg)
-		testEvents.register(added)
+		c.handleChange(t, change)
 		return
 	}
 
@@ -145,18 +142,8 @@ func matchInlineSnapshot(c *Config, t testingT, received any, inlineSnap inlineS
 		return
 	}
 
-	if !shouldUpdate(c.update) {
-		handleError(t, diff)
-		return
-	}
-
-	if err := upsertInlineSnapshot(filename, line, snapshot); err != nil {
-		handleError(t, err)
-		return
-	}
-
-	t.Log(updatedMsg)
-	testEvents.register(updated)
+	change.prev, change.diff = inlineSnap, diff
+	c.handleChange(t, change)
 }
 
 func upsertInlineSnapshot(filename string, callerLine int, snapshot string) error {
@@ -185,6 +172,11 @@ func upsertInlineSnapshot(filename string, callerLine int, snapshot string) erro
 		return traverseError
 	}
 
+	return writeFileAst(filename, fset, astFile)
+}
+
+// writeFileAst formats and writes the modified test file.
+func writeFileAst(filename string, fset *token.FileSet, astFile *ast.File) error {
 	// Validate AST before writing
 	var buf strings.Builder
 	if err := format.Node(&buf, fset, astFile); err != nil {
@@ -194,26 +186,12 @@ func upsertInlineSnapshot(filename string, callerLine int, snapshot string) erro
 		)
 	}
 
-	// Write to file
-	file, err := os.OpenFile(filename, os.O_TRUNC|os.O_WRONLY, 0o644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	if _, err := file.WriteString(buf.String()); err != nil {
-		return err
-	}
-
-	return nil
+	// the test file is replaced, so it's never left half-written
+	return writeFile(filename, []byte(buf.String()))
 }
 
 func (c *Config) takeInlineSnapshot(received any) string {
-	if c.serializer != nil {
-		return c.serializer(received)
-	}
-
-	return pretty.Sprint(received)
+	return c.serialize(received)
 }
 
 // registerInlineCallIdx is expected to be called once per file and before getting modified
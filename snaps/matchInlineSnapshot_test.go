@@ -2,6 +2,11 @@ package snaps
 
 import (
 	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/gkampitakis/go-snaps/internal/test"
@@ -62,6 +67,32 @@ func TestMatchInlineSnapshot(t *testing.T) {
 	})
 }
 
+func TestWriteFileAst(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported")
+	}
+
+	path := filepath.Join(t.TempDir(), "mock_test.go")
+	test.NoError(t, os.WriteFile(path, []byte("package mock\n\nvar x = 1\n"), 0o600))
+	test.NoError(t, os.Chmod(path, 0o640))
+
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	test.NoError(t, err)
+	astFile.Name.Name = "changed"
+
+	test.NoError(t, writeFileAst(path, fset, astFile))
+
+	info, err := os.Stat(path)
+	test.NoError(t, err)
+	test.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	test.Equal(t, "package changed\n\nvar x = 1\n", test.GetFileContent(t, path))
+	// only the test file is left in the directory
+	entries, err := os.ReadDir(filepath.Dir(path))
+	test.NoError(t, err)
+	test.Equal(t, 1, len(entries))
+}
+
 func TestGetInlineStringValue(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -64,10 +64,15 @@ func matchJSON(c *Config, t testingT, input any, matchers ...match.JSONMatcher)
 	t.Helper()
 
 	snapPath, snapPathRel := snapshotPath(c, t.Name(), false)
-	testID := testsRegistry.getTestID(snapPath, t.Name())
+	testID, err := c.snapshotID(snapPath, t.Name())
 	t.Cleanup(func() {
 		testsRegistry.reset(snapPath, t.Name())
 	})
+	if err != nil {
+		handleError(t, err)
+		return
+	}
+	c.deferWrites(t)
 
 	j, err := validateJSON(input)
 	if err != nil {
@@ -98,21 +103,14 @@ func matchJSON(c *Config, t testingT, input any, matchers ...match.JSONMatcher)
 	}
 
 	snapshot := c.takeJSONSnapshot(j)
+	meta := c.snapshotMetadata(
+		metadataField{key: "json", value: c.jsonOptions()},
+		metadataField{key: "matchers", value: matcherNames(matchers)},
+	)
+	change := c.sharedChange(testID, snapPath, snapshot, meta)
 	prevSnapshot, line, err := getPrevSnapshot(testID, snapPath)
 	if errors.Is(err, errSnapNotFound) {
-		if !shouldCreate(c.update) {
-			handleError(t, err)
-			return
-		}
-
-		err := addNewSnapshot(testID, snapshot, snapPath)
-		if err != nil {
-			handleError(t, err)
-			return
-		}
-
-		t.Log(addedMsg)
-		testEvents.register(added)
+		c.handleChange(t, change)
 		return
 	}
 	if err != nil {
@@ -126,18 +124,8 @@ func matchJSON(c *Config, t testingT, input any, matchers ...match.JSONMatcher)
 		return
 	}
 
-	if !shouldUpdate(c.update) {
-		handleError(t, diff)
-		return
-	}
-
-	if err = updateSnapshot(testID, snapshot, snapPath); err != nil {
-		handleError(t, err)
-		return
-	}
-
-	t.Log(updatedMsg)
-	testEvents.register(updated)
+	change.prev, change.line, change.diff = &prevSnapshot, line, diff
+	c.handleChange(t, change)
 }
 
 func validateJSON(input any) ([]byte, error) {
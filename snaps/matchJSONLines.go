@@ -0,0 +1,241 @@
+package snaps
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gkampitakis/go-snaps/internal/colors"
+	"github.com/gkampitakis/go-snaps/match"
+)
+
+/*
+MatchJSONLines verifies a stream of newline-delimited JSON (NDJSON) matches the most recent snap file.
+Input can be an io.Reader, []byte or string where every non-empty line is a valid json.
+
+	snaps.MatchJSONLines(t, "{\"event\":\"created\"}\n{\"event\":\"deleted\"}\n")
+	snaps.MatchJSONLines(t, resp.Body)
+
+Every record is pretty printed following snaps.JSON configuration.
+
+MatchJSONLines also supports passing matchers as a third argument. Matchers are applied on every record.
+match.CustomRecord can be used for accessing the number of the record the matcher is applied on,
+records are numbered from 1, skipping empty lines, the same as in failure messages.
+
+	snaps.MatchJSONLines(t, events, match.Any("timestamp"))
+*/
+func (c *Config) MatchJSONLines(t testingT, input any, matchers ...match.JSONMatcher) {
+	t.Helper()
+
+	matchJSONLines(c, t, input, matchers...)
+}
+
+/*
+MatchJSONLines verifies a stream of newline-delimited JSON (NDJSON) matches the most recent snap file.
+Input can be an io.Reader, []byte or string where every non-empty line is a valid json.
+
+	snaps.MatchJSONLines(t, "{\"event\":\"created\"}\n{\"event\":\"deleted\"}\n")
+	snaps.MatchJSONLines(t, resp.Body)
+
+Every record is pretty printed following snaps.JSON configuration.
+
+MatchJSONLines also supports passing matchers as a third argument. Matchers are applied on every record.
+match.CustomRecord can be used for accessing the number of the record the matcher is applied on,
+records are numbered from 1, skipping empty lines, the same as in failure messages.
+
+	snaps.MatchJSONLines(t, events, match.Any("timestamp"))
+*/
+func MatchJSONLines(t testingT, input any, matchers ...match.JSONMatcher) {
+	t.Helper()
+
+	matchJSONLines(&defaultConfig, t, input, matchers...)
+}
+
+func matchJSONLines(c *Config, t testingT, input any, matchers ...match.JSONMatcher) {
+	t.Helper()
+
+	snapPath, snapPathRel := snapshotPath(c, t.Name(), false)
+	testID, err := c.snapshotID(snapPath, t.Name())
+	t.Cleanup(func() {
+		testsRegistry.reset(snapPath, t.Name())
+	})
+	if err != nil {
+		handleError(t, err)
+		return
+	}
+	c.deferWrites(t)
+
+	records, err := validateJSONLines(input)
+	if err != nil {
+		handleError(t, err)
+		return
+	}
+
+	s := strings.Builder{}
+	for i, r := range records {
+		// records are numbered from 1 without the empty lines, same as in validateJSONLines errors
+		j, matchersErrors := applyJSONRecordMatchers(r, i+1, matchers...)
+		for _, err := range matchersErrors {
+			colors.Fprint(
+				&s,
+				colors.Red,
+				fmt.Sprintf(
+					"\n%srecord %d: match.%s(\"%s\") - %s",
+					errorSymbol,
+					i+1,
+					err.Matcher,
+					err.Path,
+					err.Reason,
+				),
+			)
+		}
+
+		records[i] = j
+	}
+	if s.Len() > 0 {
+		handleError(t, s.String())
+		return
+	}
+
+	recordSnapshots := c.takeJSONRecordSnapshots(records)
+	snapshot := strings.Join(recordSnapshots, "\n")
+	meta := c.snapshotMetadata(
+		metadataField{key: "json", value: c.jsonOptions()},
+		metadataField{key: "matchers", value: matcherNames(matchers)},
+	)
+	change := c.sharedChange(testID, snapPath, snapshot, meta)
+	prevSnapshot, line, err := getPrevSnapshot(testID, snapPath)
+	if errors.Is(err, errSnapNotFound) {
+		c.handleChange(t, change)
+		return
+	}
+	if err != nil {
+		handleError(t, err)
+		return
+	}
+
+	diff := prettyDiff(prevSnapshot, snapshot, snapPathRel, line)
+	if diff == "" {
+		testEvents.register(passed)
+		return
+	}
+
+	change.prev, change.line, change.diff = &prevSnapshot, line, changedRecordsMsg(prevSnapshot, recordSnapshots)+diff
+	c.handleChange(t, change)
+}
+
+// validateJSONLines splits input on new lines and validates each non-empty line is a valid json.
+// Invalid lines are reported with both their line and record number, as empty lines are not records.
+//
+// Returns a copy of every record, so matchers can safely modify them in place.
+func validateJSONLines(input any) ([][]byte, error) {
+	var data []byte
+
+	switch v := input.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case io.Reader:
+		b, err := io.ReadAll(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	default:
+		return nil, fmt.Errorf("unsupported json lines input type %T", input)
+	}
+
+	records := [][]byte{}
+	for i, l := range bytes.Split(data, []byte("\n")) {
+		l = bytes.TrimSpace(l)
+		if len(l) == 0 {
+			continue
+		}
+
+		if !json.Valid(l) {
+			return nil, fmt.Errorf("%w at line %d, record %d", errInvalidJSON, i+1, len(records)+1)
+		}
+
+		records = append(records, bytes.Clone(l))
+	}
+
+	return records, nil
+}
+
+func applyJSONRecordMatchers(
+	b []byte,
+	record int,
+	matchers ...match.JSONMatcher,
+) ([]byte, []match.MatcherError) {
+	errors := []match.MatcherError{}
+
+	for _, m := range matchers {
+		var (
+			json []byte
+			errs []match.MatcherError
+		)
+
+		if rm, ok := m.(match.JSONRecordMatcher); ok {
+			json, errs = rm.JSONRecord(b, record)
+		} else {
+			json, errs = m.JSON(b)
+		}
+		if len(errs) > 0 {
+			errors = append(errors, errs...)
+			continue
+		}
+		b = json
+	}
+
+	return b, errors
+}
+
+func (c *Config) takeJSONRecordSnapshots(records [][]byte) []string {
+	snapshots := make([]string, len(records))
+
+	for i, r := range records {
+		snapshots[i] = c.takeJSONSnapshot(r)
+	}
+
+	return snapshots
+}
+
+// changedRecordsMsg compares the records stored in the snapshot with the received ones
+// and returns a message with the numbers of the records that changed, starting from 1.
+func changedRecordsMsg(prevSnapshot string, records []string) string {
+	var prev []string
+
+	d := json.NewDecoder(strings.NewReader(prevSnapshot))
+	for {
+		var r json.RawMessage
+		if err := d.Decode(&r); err != nil {
+			break
+		}
+		prev = append(prev, string(r))
+	}
+
+	changed := []string{}
+	for i := 0; i < max(len(prev), len(records)); i++ {
+		if i >= len(prev) || i >= len(records) || prev[i] != records[i] {
+			changed = append(changed, fmt.Sprint(i+1))
+		}
+	}
+
+	if len(changed) == 0 {
+		return ""
+	}
+
+	subject := "record"
+	if len(changed) > 1 {
+		subject += "s"
+	}
+
+	return colors.Sprint(
+		colors.Red,
+		fmt.Sprintf("\n%s%s %s changed", errorSymbol, subject, strings.Join(changed, ", ")),
+	) + "\n"
+}
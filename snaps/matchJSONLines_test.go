@@ -0,0 +1,197 @@
+package snaps
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+	"github.com/gkampitakis/go-snaps/match"
+)
+
+const jsonLinesFilename = "matchJSONLines_test.snap"
+
+func TestMatchJSONLines(t *testing.T) {
+	t.Run("should create json lines snapshot", func(t *testing.T) {
+		expected := "{\n \"id\": 1,\n \"user\": \"mock-name\"\n}\n{\n \"id\": 2,\n \"user\": \"mock-name\"\n}"
+
+		for _, tc := range []struct {
+			name  string
+			input any
+		}{
+			{
+				name:  "string",
+				input: "{\"user\":\"mock-name\",\"id\":1}\n{\"user\":\"mock-name\",\"id\":2}\n",
+			},
+			{
+				name:  "byte",
+				input: []byte("{\"user\":\"mock-name\",\"id\":1}\n\n{\"user\":\"mock-name\",\"id\":2}"),
+			},
+			{
+				name: "reader",
+				input: strings.NewReader(
+					"{\"user\":\"mock-name\",\"id\":1}\r\n{\"user\":\"mock-name\",\"id\":2}\r\n",
+				),
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				snapPath := setupSnapshot(t, jsonLinesFilename, false)
+
+				mockT := test.NewMockTestingT(t)
+				mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+
+				MatchJSONLines(mockT, tc.input)
+
+				snap, line, err := getPrevSnapshot("[mock-name - 1]", snapPath)
+
+				test.NoError(t, err)
+				test.Equal(t, 2, line)
+				test.Equal(t, expected, snap)
+				test.Equal(t, 1, testEvents.items[added])
+				// clean up function called
+				test.Equal(t, 0, testsRegistry.running[snapPath]["mock-name"])
+				test.Equal(t, 1, testsRegistry.cleanup[snapPath]["mock-name"])
+			})
+		}
+	})
+
+	t.Run("should validate json lines", func(t *testing.T) {
+		for _, tc := range []struct {
+			name  string
+			input any
+			err   string
+		}{
+			{
+				name:  "invalid line",
+				input: "{\"user\":\"mock-name\"}\n{\"user\"\n",
+				err:   "invalid json at line 2, record 2",
+			},
+			{
+				name:  "invalid line after empty lines",
+				input: "{\"user\":\"mock-name\"}\n\n  \n{\"user\"\n",
+				err:   "invalid json at line 4, record 2",
+			},
+			{
+				name:  "unsupported type",
+				input: 10,
+				err:   "unsupported json lines input type int",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				setupSnapshot(t, jsonLinesFilename, false)
+
+				mockT := test.NewMockTestingT(t)
+				mockT.MockError = func(args ...any) {
+					test.Equal(t, tc.err, args[0].(error).Error())
+				}
+
+				MatchJSONLines(mockT, tc.input)
+			})
+		}
+	})
+
+	t.Run("matchers", func(t *testing.T) {
+		t.Run("should apply matchers on every record", func(t *testing.T) {
+			snapPath := setupSnapshot(t, jsonLinesFilename, false)
+
+			mockT := test.NewMockTestingT(t)
+			mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+
+			MatchJSONLines(
+				mockT,
+				// empty lines are not records
+				"{\"id\":\"a\",\"ts\":1}\n\n{\"id\":\"b\",\"ts\":2}",
+				match.Any("ts"),
+				match.CustomRecord("id", func(record int, val any) (any, error) {
+					return fmt.Sprintf("<id %d>", record), nil
+				}),
+			)
+
+			test.Equal(
+				t,
+				"\n[mock-name - 1]\n{\n \"id\": \"<id 1>\",\n \"ts\": \"<Any value>\"\n}\n"+
+					"{\n \"id\": \"<id 2>\",\n \"ts\": \"<Any value>\"\n}\n---\n",
+				test.GetFileContent(t, snapPath),
+			)
+		})
+
+		t.Run("should aggregate errors from matchers with record number", func(t *testing.T) {
+			setupSnapshot(t, jsonLinesFilename, false)
+
+			mockT := test.NewMockTestingT(t)
+			mockT.MockError = func(args ...any) {
+				test.Equal(
+					t,
+					"\x1b[31;1m\n✕ record 2: match.Custom(\"age\") - mock error\x1b[0m",
+					args[0],
+				)
+			}
+
+			c := func(val any) (any, error) {
+				if val.(float64) > 10 {
+					return nil, errors.New("mock error")
+				}
+				return val, nil
+			}
+			MatchJSONLines(
+				mockT,
+				// empty lines are not records
+				"{\"age\":10}\n\n{\"age\":20}",
+				match.Custom("age", c),
+			)
+		})
+	})
+
+	t.Run("should report which records changed", func(t *testing.T) {
+		setupSnapshot(t, jsonLinesFilename, false)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+		mockT.MockError = func(args ...any) {
+			test.Contains(t, args[0].(string), "✕ records 2, 3 changed")
+		}
+
+		MatchJSONLines(mockT, "{\"id\":1}\n{\"id\":2}")
+		test.Equal(t, 1, testEvents.items[added])
+
+		// Resetting registry to emulate the same MatchJSONLines call
+		testsRegistry = newRegistry()
+
+		MatchJSONLines(mockT, "{\"id\":1}\n{\"id\":3}\n{\"id\":4}")
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+
+	t.Run("should update snapshot when 'shouldUpdate'", func(t *testing.T) {
+		snapPath := setupSnapshot(t, jsonLinesFilename, false, "true")
+
+		printerExpectedCalls := []func(received any){
+			func(received any) { test.Equal(t, addedMsg, received.(string)) },
+			func(received any) { test.Equal(t, updatedMsg, received.(string)) },
+		}
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) {
+			printerExpectedCalls[0](args[0])
+
+			// shift
+			printerExpectedCalls = printerExpectedCalls[1:]
+		}
+
+		// First call for creating the snapshot
+		MatchJSONLines(mockT, "{\"value\":\"hello world\"}")
+		test.Equal(t, 1, testEvents.items[added])
+
+		// Resetting registry to emulate the same MatchJSONLines call
+		testsRegistry = newRegistry()
+
+		// Second call with different params
+		MatchJSONLines(mockT, "{\"value\":\"bye world\"}\n{\"value\":\"again\"}")
+
+		test.Equal(
+			t,
+			"\n[mock-name - 1]\n{\n \"value\": \"bye world\"\n}\n{\n \"value\": \"again\"\n}\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+		test.Equal(t, 1, testEvents.items[updated])
+	})
+}
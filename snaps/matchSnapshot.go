@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/gkampitakis/go-snaps/internal/colors"
-	"github.com/kr/pretty"
 )
 
 /*
@@ -55,27 +54,22 @@ func matchSnapshot(c *Config, t testingT, values ...any) {
 	}
 
 	snapPath, snapPathRel := snapshotPath(c, t.Name(), false)
-	testID := testsRegistry.getTestID(snapPath, t.Name())
+	testID, err := c.snapshotID(snapPath, t.Name())
 	t.Cleanup(func() {
 		testsRegistry.reset(snapPath, t.Name())
 	})
+	if err != nil {
+		handleError(t, err)
+		return
+	}
+	c.deferWrites(t)
 
 	snapshot := c.takeSnapshot(values)
+	meta := c.snapshotMetadata(metadataField{key: "serializer", value: c.serializerName()})
+	change := c.sharedChange(testID, snapPath, snapshot, meta)
 	prevSnapshot, line, err := getPrevSnapshot(testID, snapPath)
 	if errors.Is(err, errSnapNotFound) {
-		if !shouldCreate(c.update) {
-			handleError(t, err)
-			return
-		}
-
-		err := addNewSnapshot(testID, snapshot, snapPath)
-		if err != nil {
-			handleError(t, err)
-			return
-		}
-
-		t.Log(addedMsg)
-		testEvents.register(added)
+		c.handleChange(t, change)
 		return
 	}
 	if err != nil {
@@ -84,8 +78,8 @@ func matchSnapshot(c *Config, t testingT, values ...any) {
 	}
 
 	diff := prettyDiff(
-		unescapeEndChars(prevSnapshot),
-		unescapeEndChars(snapshot),
+		prevSnapshot,
+		snapshot,
 		snapPathRel,
 		line,
 	)
@@ -94,30 +88,16 @@ func matchSnapshot(c *Config, t testingT, values ...any) {
 		return
 	}
 
-	if !shouldUpdate(c.update) {
-		handleError(t, diff)
-		return
-	}
-
-	if err = updateSnapshot(testID, snapshot, snapPath); err != nil {
-		handleError(t, err)
-		return
-	}
-
-	t.Log(updatedMsg)
-	testEvents.register(updated)
+	change.prev, change.line, change.diff = &prevSnapshot, line, diff
+	c.handleChange(t, change)
 }
 
 func (c *Config) takeSnapshot(objects []any) string {
 	snapshots := make([]string, len(objects))
 
 	for i, object := range objects {
-		if c.serializer != nil {
-			snapshots[i] = c.serializer(object)
-		} else {
-			snapshots[i] = pretty.Sprint(object)
-		}
+		snapshots[i] = c.serialize(object)
 	}
 
-	return escapeEndChars(strings.Join(snapshots, "\n"))
+	return strings.Join(snapshots, "\n")
 }
@@ -49,6 +49,8 @@ func setupSnapshot(t *testing.T, file string, ci bool, update ...string) string
 		os.Remove(snapPath)
 		testsRegistry = newRegistry()
 		standaloneTestsRegistry = newStandaloneRegistry()
+		storages = newStorageRegistry()
+		snapshotFiles = newSnapshotIndex()
 		testEvents = newTestEvents()
 		isCI = ciinfo.IsCI
 		updateVAR = updateVARPrev
@@ -80,6 +82,46 @@ func TestMatchSnapshot(t *testing.T) {
 		test.Equal(t, 1, testsRegistry.cleanup[snapPath]["mock-name"])
 	})
 
+	t.Run("should create named snapshot", func(t *testing.T) {
+		snapPath := setupSnapshot(t, fileName, false)
+		mockT := test.NewMockTestingT(t)
+		mockT.MockCleanup = func(func()) {}
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+
+		MatchSnapshot(mockT, "first")
+		WithConfig(Name("after-login")).MatchSnapshot(mockT, 10)
+		MatchSnapshot(mockT, "second")
+
+		test.Equal(
+			t,
+			"\n[mock-name - 1]\nfirst\n---\n\n[mock-name - after-login]\nint(10)\n---\n"+
+				"\n[mock-name - 2]\nsecond\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+		test.Equal(t, 3, testEvents.items[added])
+	})
+
+	t.Run("should error on duplicate snapshot name", func(t *testing.T) {
+		setupSnapshot(t, fileName, false)
+		mockT := test.NewMockTestingT(t)
+		mockT.MockCleanup = func(func()) {}
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+		mockT.MockError = func(args ...any) {
+			test.Equal(
+				t,
+				"snapshot name used more than once: \"same\" in mock-name",
+				args[0].(error).Error(),
+			)
+		}
+
+		s := WithConfig(Name("same"))
+		s.MatchSnapshot(mockT, 10)
+		s.MatchSnapshot(mockT, 20)
+
+		test.Equal(t, 1, testEvents.items[added])
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+
 	t.Run("if it's running on ci should skip creating snapshot", func(t *testing.T) {
 		setupSnapshot(t, fileName, true)
 
@@ -252,6 +294,86 @@ func TestMatchSnapshot(t *testing.T) {
 		})
 	})
 
+	t.Run("should only create and update snapshots of tests matching UPDATE_SNAPS pattern", func(t *testing.T) {
+		snapPath := setupSnapshot(t, fileName, false, "")
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+		MatchSnapshot(mockT, "hello world")
+
+		updateVAR = "^mock-name$"
+		testsRegistry = newRegistry()
+		MatchSnapshot(mockT, "bye world")
+
+		test.Equal(t, "\n[mock-name - 1]\nbye world\n---\n", test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[updated])
+
+		// not matching tests are compared and fail
+		updateVAR = "^other$"
+		testsRegistry = newRegistry()
+		mockT.MockError = func(args ...any) { test.Contains(t, args[0].(string), "hello") }
+		MatchSnapshot(mockT, "hello world")
+
+		mockT.MockName = func() string { return "mock-name/new" }
+		mockT.MockError = func(args ...any) { test.Equal(t, errSnapNotFound, args[0].(error)) }
+		MatchSnapshot(mockT, "new")
+
+		test.Equal(t, "\n[mock-name - 1]\nbye world\n---\n", test.GetFileContent(t, snapPath))
+		test.Equal(t, 2, testEvents.items[erred])
+	})
+
+	t.Run("should create but not update snapshots on CI with PolicyNew", func(t *testing.T) {
+		snapPath := setupSnapshot(t, fileName, true, "new")
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+		MatchSnapshot(mockT, "hello world")
+
+		testsRegistry = newRegistry()
+		mockT.MockError = func(args ...any) { test.Contains(t, args[0].(string), "hello") }
+		MatchSnapshot(mockT, "bye world")
+
+		test.Equal(t, "\n[mock-name - 1]\nhello world\n---\n", test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[added])
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+
+	t.Run("should not treat boolean and policy UPDATE_SNAPS values as patterns", func(t *testing.T) {
+		t.Cleanup(func() { updateVAR = "" })
+
+		for _, v := range []string{"", "false", "0", "1", "true", "always", "clean", "pending"} {
+			updateVAR = v
+			test.True(t, updatePattern() == nil)
+		}
+	})
+
+	t.Run("should only update tests matching the UPDATE_SNAPS pattern", func(t *testing.T) {
+		t.Cleanup(func() { updateVAR = "" })
+
+		updateVAR = "TestAPI/users/.*"
+		test.True(t, shouldUpdate("", "TestAPI/users/create"))
+		test.False(t, shouldUpdate("", "TestAPI/orders"))
+		test.False(t, shouldCreate("", "TestAPI/orders"))
+
+		updateVAR = "Test[" // invalid patterns match nothing
+		test.False(t, shouldUpdate("", "Test["))
+	})
+
+	t.Run("should fail on invalid UPDATE_SNAPS values", func(t *testing.T) {
+		snapPath := setupSnapshot(t, fileName, false, "updte")
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) {
+			test.Contains(t, args[0].(error).Error(), `UPDATE_SNAPS: invalid update policy "updte"`)
+		}
+		MatchSnapshot(mockT, "hello world")
+
+		_, err := os.Stat(snapPath)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+		test.Equal(t, 1, testEvents.items[erred])
+
+		_, err = Clean(nil)
+		test.Contains(t, err.Error(), `UPDATE_SNAPS: invalid update policy "updte"`)
+	})
+
 	t.Run("should print warning if no params provided", func(t *testing.T) {
 		mockT := test.NewMockTestingT(t)
 		mockT.MockLog = func(args ...any) {
@@ -333,7 +455,7 @@ func TestMatchSnapshot(t *testing.T) {
 				"- hello world----\x1b[0m\n\x1b[38;5;52m\x1b[48;5;225m- ---\x1b[0m\n\x1b[38;5;22m\x1b[48;5;159m" +
 				"+ int(100)\x1b[0m\n\x1b[38;5;22m\x1b[48;5;159m+ bye world----\x1b[0m\n\x1b[38;5;22m\x1b[48;5;159m" +
 				"+ --\x1b[0m\n\n\x1b[2mat " + filepath.FromSlash(
-				"__snapshots__/matchSnapshot_test.snap:2",
+				"__snapshots__/matchSnapshot_test.snap:3",
 			) +
 				"\n\x1b[0m"
 
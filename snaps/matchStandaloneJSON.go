@@ -72,10 +72,18 @@ func matchStandaloneJSON(c *Config, t testingT, input any, matchers ...match.JSO
 	t.Helper()
 
 	genericPathSnap, genericSnapPathRel := snapshotPath(c, t.Name(), true)
-	snapPath, snapPathRel := standaloneTestsRegistry.getTestID(genericPathSnap, genericSnapPathRel)
+	snapPath, snapPathRel, err := c.standaloneSnapshotPath(
+		t.Name(),
+		genericPathSnap,
+		genericSnapPathRel,
+	)
 	t.Cleanup(func() {
 		standaloneTestsRegistry.reset(genericPathSnap)
 	})
+	if err != nil {
+		handleError(t, err)
+		return
+	}
 
 	j, err := validateJSON(input)
 	if err != nil {
@@ -106,21 +114,10 @@ func matchStandaloneJSON(c *Config, t testingT, input any, matchers ...match.JSO
 	}
 
 	snapshot := c.takeJSONSnapshot(j)
+	change := c.standaloneChange(t.Name(), snapPath, snapshot)
 	prevSnapshot, err := getPrevStandaloneSnapshot(snapPath)
 	if errors.Is(err, errSnapNotFound) {
-		if !shouldCreate(c.update) {
-			handleError(t, err)
-			return
-		}
-
-		err := upsertStandaloneSnapshot(snapshot, snapPath)
-		if err != nil {
-			handleError(t, err)
-			return
-		}
-
-		t.Log(addedMsg)
-		testEvents.register(added)
+		c.handleChange(t, change)
 		return
 	}
 	if err != nil {
@@ -139,16 +136,6 @@ func matchStandaloneJSON(c *Config, t testingT, input any, matchers ...match.JSO
 		return
 	}
 
-	if !shouldUpdate(c.update) {
-		handleError(t, diff)
-		return
-	}
-
-	if err = upsertStandaloneSnapshot(snapshot, snapPath); err != nil {
-		handleError(t, err)
-		return
-	}
-
-	t.Log(updatedMsg)
-	testEvents.register(updated)
+	change.prev, change.line, change.diff = &prevSnapshot, 1, diff
+	c.handleChange(t, change)
 }
@@ -2,8 +2,6 @@ package snaps
 
 import (
 	"errors"
-
-	"github.com/kr/pretty"
 )
 
 /*
@@ -39,38 +37,31 @@ func MatchStandaloneSnapshot(t testingT, input any) {
 }
 
 func (c *Config) takeStandaloneSnapshot(input any) string {
-	if c.serializer != nil {
-		return c.serializer(input)
-	}
-
-	return pretty.Sprint(input)
+	return c.serialize(input)
 }
 
 func matchStandaloneSnapshot(c *Config, t testingT, input any) {
 	t.Helper()
 
 	genericPathSnap, genericSnapPathRel := snapshotPath(c, t.Name(), true)
-	snapPath, snapPathRel := standaloneTestsRegistry.getTestID(genericPathSnap, genericSnapPathRel)
+	snapPath, snapPathRel, err := c.standaloneSnapshotPath(
+		t.Name(),
+		genericPathSnap,
+		genericSnapPathRel,
+	)
 	t.Cleanup(func() {
 		standaloneTestsRegistry.reset(genericPathSnap)
 	})
+	if err != nil {
+		handleError(t, err)
+		return
+	}
 
 	snapshot := c.takeStandaloneSnapshot(input)
+	change := c.standaloneChange(t.Name(), snapPath, snapshot)
 	prevSnapshot, err := getPrevStandaloneSnapshot(snapPath)
 	if errors.Is(err, errSnapNotFound) {
-		if !shouldCreate(c.update) {
-			handleError(t, err)
-			return
-		}
-
-		err := upsertStandaloneSnapshot(snapshot, snapPath)
-		if err != nil {
-			handleError(t, err)
-			return
-		}
-
-		t.Log(addedMsg)
-		testEvents.register(added)
+		c.handleChange(t, change)
 		return
 	}
 	if err != nil {
@@ -89,16 +80,6 @@ func matchStandaloneSnapshot(c *Config, t testingT, input any) {
 		return
 	}
 
-	if !shouldUpdate(c.update) {
-		handleError(t, diff)
-		return
-	}
-
-	if err = upsertStandaloneSnapshot(snapshot, snapPath); err != nil {
-		handleError(t, err)
-		return
-	}
-
-	t.Log(updatedMsg)
-	testEvents.register(updated)
+	change.prev, change.line, change.diff = &prevSnapshot, 1, diff
+	c.handleChange(t, change)
 }
@@ -1,6 +1,7 @@
 package snaps
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -61,6 +62,53 @@ func TestMatchStandaloneSnapshot(t *testing.T) {
 		})
 	})
 
+	t.Run("should create named snapshot", func(t *testing.T) {
+		snapPath := setupSnapshot(t, "mock-name_response.snap", false)
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+
+		WithConfig(Name("response")).MatchStandaloneSnapshot(mockT, "hello world")
+
+		test.Equal(t, "hello world", test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[added])
+		test.Equal(t, 0, standaloneTestsRegistry.running[snapPath])
+		test.True(t, standaloneTestsRegistry.cleanupNames.Has(snapPath))
+		// named snapshots don't count as occurrences
+		test.Equal(t, 0, len(standaloneTestsRegistry.cleanup))
+	})
+
+	t.Run("should error on duplicate named snapshot", func(t *testing.T) {
+		snapPath := setupSnapshot(t, "mock-name_response.snap", false)
+		mockT := test.NewMockTestingT(t)
+		mockT.MockCleanup = func(func()) {}
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+		mockT.MockError = func(args ...any) {
+			test.Equal(
+				t,
+				"snapshot name used more than once: \"response\" in mock-name",
+				args[0].(error).Error(),
+			)
+		}
+
+		s := WithConfig(Name("response"))
+		s.MatchStandaloneSnapshot(mockT, "hello world")
+		s.MatchStandaloneSnapshot(mockT, "bye world")
+
+		test.Equal(t, "hello world", test.GetFileContent(t, snapPath))
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+
+	t.Run("should error on invalid name", func(t *testing.T) {
+		setupSnapshot(t, "mock-name_response.snap", false)
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) {
+			test.True(t, errors.Is(args[0].(error), errInvalidName))
+		}
+
+		WithConfig(Name("../response")).MatchStandaloneSnapshot(mockT, "hello world")
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+
 	t.Run("should create snapshot", func(t *testing.T) {
 		snapPath := setupSnapshot(t, standaloneFilename, false)
 		mockT := test.NewMockTestingT(t)
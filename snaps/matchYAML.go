@@ -59,10 +59,15 @@ func matchYAML(c *Config, t testingT, input any, matchers ...match.YAMLMatcher)
 	t.Helper()
 
 	snapPath, snapPathRel := snapshotPath(c, t.Name(), false)
-	testID := testsRegistry.getTestID(snapPath, t.Name())
+	testID, err := c.snapshotID(snapPath, t.Name())
 	t.Cleanup(func() {
 		testsRegistry.reset(snapPath, t.Name())
 	})
+	if err != nil {
+		handleError(t, err)
+		return
+	}
+	c.deferWrites(t)
 
 	y, err := validateYAML(input)
 	if err != nil {
@@ -93,21 +98,11 @@ func matchYAML(c *Config, t testingT, input any, matchers ...match.YAMLMatcher)
 	}
 
 	snapshot := takeYAMLSnapshot(y)
+	meta := c.snapshotMetadata(metadataField{key: "matchers", value: matcherNames(matchers)})
+	change := c.sharedChange(testID, snapPath, snapshot, meta)
 	prevSnapshot, line, err := getPrevSnapshot(testID, snapPath)
 	if errors.Is(err, errSnapNotFound) {
-		if !shouldCreate(c.update) {
-			handleError(t, err)
-			return
-		}
-
-		err := addNewSnapshot(testID, snapshot, snapPath)
-		if err != nil {
-			handleError(t, err)
-			return
-		}
-
-		t.Log(addedMsg)
-		testEvents.register(added)
+		c.handleChange(t, change)
 		return
 	}
 	if err != nil {
@@ -116,8 +111,8 @@ func matchYAML(c *Config, t testingT, input any, matchers ...match.YAMLMatcher)
 	}
 
 	diff := prettyDiff(
-		unescapeEndChars(prevSnapshot),
-		unescapeEndChars(snapshot),
+		prevSnapshot,
+		snapshot,
 		snapPathRel,
 		line,
 	)
@@ -126,18 +121,8 @@ func matchYAML(c *Config, t testingT, input any, matchers ...match.YAMLMatcher)
 		return
 	}
 
-	if !shouldUpdate(c.update) {
-		handleError(t, diff)
-		return
-	}
-
-	if err = updateSnapshot(testID, snapshot, snapPath); err != nil {
-		handleError(t, err)
-		return
-	}
-
-	t.Log(updatedMsg)
-	testEvents.register(updated)
+	change.prev, change.line, change.diff = &prevSnapshot, line, diff
+	c.handleChange(t, change)
 }
 
 func validateYAML(input any) ([]byte, error) {
@@ -185,5 +170,5 @@ func applyYAMLMatchers(b []byte, matchers ...match.YAMLMatcher) ([]byte, []match
 }
 
 func takeYAMLSnapshot(b []byte) string {
-	return escapeEndChars(string(b))
+	return string(b)
 }
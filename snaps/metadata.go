@@ -0,0 +1,117 @@
+package snaps
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const modulePath = "github.com/gkampitakis/go-snaps"
+
+// snapshotMetadata is stored along with a snapshot when snaps.Metadata is used.
+type snapshotMetadata struct {
+	// header is written when a legacy format file is upgraded
+	header metadata
+	entry  metadata
+}
+
+// snapsVersion returns the version of go-snaps in use.
+var snapsVersion = sync.OnceValue(func() string {
+	bInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+
+	if bInfo.Main.Path == modulePath {
+		return bInfo.Main.Version
+	}
+	for _, dep := range bInfo.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+
+	return "(devel)"
+})
+
+// snapshotMetadata returns the metadata stored with a snapshot, if snaps.Metadata or snaps.Frozen is used.
+func (c *Config) snapshotMetadata(entry ...metadataField) *snapshotMetadata {
+	var meta *snapshotMetadata
+	if c.metadata {
+		// the header is shared by snapshots written with different configs, so the serializer
+		// and json options are only stored with each snapshot
+		meta = &snapshotMetadata{
+			header: metadata{{key: "go-snaps", value: snapsVersion()}},
+			entry:  entry,
+		}
+	}
+	if c.frozen {
+		meta = meta.with(metadataField{key: frozenKey, value: "true"})
+	}
+
+	return meta
+}
+
+// with returns the metadata with field appended to the entry. Fields like the frozen marker are
+// metadata lines, so they're stored along with the go-snaps version even if snaps.Metadata is not used.
+func (m *snapshotMetadata) with(field metadataField) *snapshotMetadata {
+	if m == nil {
+		m = &snapshotMetadata{header: metadata{{key: "go-snaps", value: snapsVersion()}}}
+	}
+
+	return &snapshotMetadata{
+		header: m.header,
+		entry:  append(slices.Clone(m.entry), field),
+	}
+}
+
+// serializerName returns the name of the serializer used for non-structured snapshots.
+func (c *Config) serializerName() string {
+	switch {
+	case c.serializer != nil:
+		return "custom"
+	case c.goSerializer != nil:
+		return "go"
+	default:
+		return "pretty"
+	}
+}
+
+// jsonOptions returns the json format options used for json snapshots.
+func (c *Config) jsonOptions() string {
+	o := c.json.getPrettyJSONOptions()
+
+	return fmt.Sprintf("width=%d indent=%q sort-keys=%t", o.Width, o.Indent, o.SortKeys)
+}
+
+// matcherNames returns the matchers as written in code, comma separated.
+func matcherNames[M any](matchers []M) string {
+	names := make([]string, len(matchers))
+	for i, m := range matchers {
+		if s, ok := any(m).(fmt.Stringer); ok {
+			names[i] = s.String()
+			continue
+		}
+
+		names[i] = fmt.Sprintf("%T", m)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+/*
+UpgradeFile rewrites a snapshot file in the legacy format, without a header, to the current format
+with a metadata header. Files already in the current format are left unchanged.
+
+	snaps.UpgradeFile("__snapshots__/my_test.snap")
+*/
+func UpgradeFile(snapPath string) error {
+	f, err := snapshotFiles.load(snapPath)
+	if err != nil {
+		return err
+	}
+
+	return f.upgrade(snapPath, metadata{{key: "go-snaps", value: snapsVersion()}})
+}
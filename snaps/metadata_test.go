@@ -0,0 +1,126 @@
+package snaps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+	"github.com/gkampitakis/go-snaps/match"
+)
+
+const metadataFilename = "metadata_test.snap"
+
+func TestMetadata(t *testing.T) {
+	t.Run("should write metadata header and entries", func(t *testing.T) {
+		snapPath := setupSnapshot(t, metadataFilename, false)
+		c := WithConfig(Metadata(), Filename("metadata_test"))
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+
+		c.MatchJSON(mockT, `{"user":"mock-name","id":10}`, match.Any("id"))
+		mockT.MockName = func() string { return "mock-name-2" }
+		c.MatchSnapshot(mockT, "#@ not metadata")
+
+		test.Equal(
+			t,
+			"# format: 2\n# go-snaps: "+snapsVersion()+"\n"+
+				"\n[mock-name - 1]\n#@ json: width=0 indent=\" \" sort-keys=true\n#@ matchers: match.Any(\"id\")\n"+
+				"{\n \"id\": \"<Any value>\",\n \"user\": \"mock-name\"\n}\n---\n"+
+				"\n[mock-name-2 - 1]\n#@ serializer: pretty\n\\#@ not metadata\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+
+		// snapshots are read back without the metadata
+		snap, line, err := getPrevSnapshot("[mock-name-2 - 1]", snapPath)
+		test.NoError(t, err)
+		test.Equal(t, "#@ not metadata", snap)
+		test.Equal(t, 13, line)
+
+		f, err := parseSnapshotFile([]byte(test.GetFileContent(t, snapPath)))
+		test.NoError(t, err)
+		test.Equal(t, "pretty", f.metadata(f.entries["[mock-name-2 - 1]"]).get("serializer"))
+		test.Equal(t, "", f.header().get("serializer"))
+	})
+
+	t.Run("should store the config of each snapshot with the snapshot", func(t *testing.T) {
+		snapPath := setupSnapshot(t, metadataFilename, false)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+
+		WithConfig(Metadata(), Filename("metadata_test")).MatchSnapshot(mockT, 1)
+		mockT.MockName = func() string { return "mock-name-2" }
+		WithConfig(Metadata(), Filename("metadata_test"), GoSerializer(GoSerializerConfig{})).MatchSnapshot(mockT, 2)
+		mockT.MockName = func() string { return "mock-name-3" }
+		WithConfig(Metadata(), Filename("metadata_test"), JSON(JSONConfig{Width: 20, Indent: "  "})).MatchJSON(mockT, `{"a":1}`)
+
+		test.Equal(
+			t,
+			"# format: 2\n# go-snaps: "+snapsVersion()+"\n"+
+				"\n[mock-name - 1]\n#@ serializer: pretty\nint(1)\n---\n"+
+				"\n[mock-name-2 - 1]\n#@ serializer: go\nint(2)\n---\n"+
+				"\n[mock-name-3 - 1]\n#@ json: width=20 indent=\"  \" sort-keys=false\n{\n  \"a\": 1\n}\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+	})
+
+	t.Run("should not write metadata by default", func(t *testing.T) {
+		snapPath := setupSnapshot(t, metadataFilename, false)
+		c := WithConfig(Filename("metadata_test"))
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+
+		c.MatchSnapshot(mockT, "value")
+
+		test.Equal(t, "\n[mock-name - 1]\nvalue\n---\n", test.GetFileContent(t, snapPath))
+	})
+
+	t.Run("should read legacy files", func(t *testing.T) {
+		f, err := parseSnapshotFile([]byte("\n[TestLegacy - 1]\n#@ serializer: go\n---\n"))
+		test.NoError(t, err)
+
+		snap, _, ok := f.get("[TestLegacy - 1]")
+		test.True(t, ok)
+		test.Equal(t, "#@ serializer: go", snap)
+		test.Equal(t, legacyFormat, f.format)
+	})
+}
+
+func TestUpgradeFile(t *testing.T) {
+	t.Run("should upgrade legacy file", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__", "upgrade.snap")
+		test.NoError(t, os.MkdirAll(filepath.Dir(snapPath), 0o755))
+		test.NoError(
+			t,
+			os.WriteFile(snapPath, []byte("\n[TestA - 1]\nvalue\n---\n\n[TestB - 1]\n/-/-/-/\n---\n"), 0o644),
+		)
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		test.NoError(t, UpgradeFile(snapPath))
+
+		test.Equal(
+			t,
+			"# format: 2\n# go-snaps: "+snapsVersion()+"\n\n[TestA - 1]\nvalue\n---\n\n[TestB - 1]\n\\---\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+
+		snap, _, err := getPrevSnapshot("[TestB - 1]", snapPath)
+		test.NoError(t, err)
+		test.Equal(t, "---", snap)
+	})
+
+	t.Run("should leave current format file unchanged", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__", "upgrade.snap")
+		content := "# format: 2\n\n[TestA - 1]\nvalue\n---\n"
+		test.NoError(t, os.MkdirAll(filepath.Dir(snapPath), 0o755))
+		test.NoError(t, os.WriteFile(snapPath, []byte(content), 0o644))
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		test.NoError(t, UpgradeFile(snapPath))
+
+		test.Equal(t, content, test.GetFileContent(t, snapPath))
+	})
+}
@@ -0,0 +1,459 @@
+package snaps
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gkampitakis/go-snaps/internal/colors"
+)
+
+/*
+Pending snapshots are written, when running with UPDATE_SNAPS=pending, to `.snap.new` files next to
+the snapshots they would replace, instead of updating them. They are snapshot files with a header
+describing what they are pending for
+
+	# format: 2
+	# pending: shared
+
+	[TestUser - 1]
+	<snapshot>
+	---
+
+and can be accepted, merging them into the snapshots, or rejected.
+*/
+const (
+	pendingExt = ".new"
+	pendingKey = "pending"
+
+	// pendingShared entries are merged into the snapshot file with the same test id
+	pendingShared = "shared"
+	// pendingStandalone files contain a single entry replacing the standalone snapshot file
+	pendingStandalone = "standalone"
+	// pendingInline files are next to the test file, with entries in the form [<test name>:<call index>]
+	pendingInline = "inline"
+)
+
+var (
+	pendingMsg        = colors.Sprint(colors.Yellow, updateSymbol+"Snapshot pending review")
+	errInvalidPending = errors.New("invalid pending snapshot file")
+)
+
+// shouldPend determines whether snapshots should be written as pending instead of being created or updated
+func shouldPend(p Policy) bool {
+	if updateVAR != "pending" || isCI || isDryRun() {
+		return false
+	}
+
+	return p != PolicyNever
+}
+
+// handlePending fails the test, as the snapshot is not accepted yet, and reports where it was written.
+func handlePending(t testingT, diff string, err error) {
+	t.Helper()
+	if err != nil {
+		handleError(t, err)
+		return
+	}
+
+	t.Error(diff + pendingMsg)
+	testEvents.register(pending)
+}
+
+func pendingMetadata(kind string, meta *snapshotMetadata) *snapshotMetadata {
+	m := &snapshotMetadata{header: metadata{{key: pendingKey, value: kind}}}
+	if meta != nil {
+		m.header = append(m.header, meta.header...)
+		m.entry = meta.entry
+	}
+
+	return m
+}
+
+// addPendingSnapshot writes the snapshot to the pending file of the snapshot file.
+func (c *Config) addPendingSnapshot(testID, snapshot, snapPath string, meta *snapshotMetadata) error {
+	snapshot, meta, err := c.externalize(snapPath, snapshot, meta)
+	if err != nil {
+		return err
+	}
+
+	return writePending(snapPath+pendingExt, testID, snapshot, pendingMetadata(pendingShared, meta))
+}
+
+// addPendingStandaloneSnapshot writes the snapshot to the pending file of the standalone snapshot.
+func addPendingStandaloneSnapshot(testName, snapshot, snapPath string) error {
+	return writePending(
+		snapPath+pendingExt,
+		standalonePendingID(testName),
+		snapshot,
+		pendingMetadata(pendingStandalone, nil),
+	)
+}
+
+// addPendingInlineSnapshot writes the snapshot to the pending file next to the test file.
+//
+// The snapshot is stored by the index of the MatchInlineSnapshot call in the test file, which unlike
+// its line doesn't change when other inline snapshots are accepted.
+func addPendingInlineSnapshot(testName, filename string, line int, snapshot string) error {
+	idx := inlineSnapshotLineMapping.GetIndex(filename, line)
+	if idx == -1 {
+		return errLocateCall
+	}
+
+	return writePending(
+		filename+snapsExt+pendingExt,
+		inlinePendingID(testName, idx),
+		snapshot,
+		pendingMetadata(pendingInline, nil),
+	)
+}
+
+// standalonePendingID is the id of a standalone snapshot in its pending file.
+func standalonePendingID(testName string) string {
+	return "[" + testName + " - standalone]"
+}
+
+// inlinePendingID is the id of an inline snapshot in the pending file of the test file,
+// numbered by the index of its MatchInlineSnapshot call.
+func inlinePendingID(testName string, idx int) string {
+	return "[" + testName + " - " + strconv.Itoa(idx) + "]"
+}
+
+func writePending(pendingPath, testID, snapshot string, meta *snapshotMetadata) error {
+	f, err := snapshotFiles.load(pendingPath)
+	if err != nil {
+		return err
+	}
+
+	return f.add(pendingPath, testID, snapshot, meta, false)
+}
+
+func isPendingFile(path string) bool {
+	return strings.HasSuffix(path, snapsExt+pendingExt)
+}
+
+/*
+PendingFiles returns the pending snapshot files, written when running with UPDATE_SNAPS=pending,
+inside the directory, searched recursively, or the path itself if it's a pending file.
+*/
+func PendingFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if !isPendingFile(path) {
+			return nil, fmt.Errorf("%w: %s", errInvalidPending, path)
+		}
+
+		return []string{path}, nil
+	}
+
+	files := []string{}
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && isPendingFile(p) {
+			files = append(files, p)
+		}
+
+		return nil
+	})
+	slices.Sort(files)
+
+	return files, err
+}
+
+/*
+AcceptPending merges the pending snapshots inside path, a directory searched recursively or a pending file,
+into the snapshots they were written for and removes the pending files.
+
+	snaps.AcceptPending("./...")
+*/
+func AcceptPending(path string) error {
+	return forEachPending(path, acceptPendingFile)
+}
+
+// RejectPending removes the pending snapshots inside path, a directory searched recursively or a pending file.
+func RejectPending(path string) error {
+	return forEachPending(path, func(string) error { return nil })
+}
+
+func forEachPending(path string, fn func(pendingPath string) error) error {
+	files, err := PendingFiles(strings.TrimSuffix(path, "..."))
+	if err != nil {
+		return err
+	}
+
+	for _, pendingPath := range files {
+		if err := fn(pendingPath); err != nil {
+			return err
+		}
+
+		snapshotFiles.invalidate(pendingPath)
+		if err := storageFor(pendingPath).Delete(pendingPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func acceptPendingFile(pendingPath string) error {
+	snapshots, err := ReadPending(pendingPath)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range snapshots {
+		if err := p.apply(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PendingSnapshot is a snapshot inside a pending file, waiting for review.
+type PendingSnapshot struct {
+	// File is the pending file
+	File string
+	// Target is the snapshot file, or the test file for inline snapshots, the snapshot is pending for
+	Target string
+	// ID is the snapshot id without the brackets e.g. "TestName - 1"
+	ID string
+	// Snapshot is the pending snapshot
+	Snapshot string
+	// Diff is the diff between the current and the pending snapshot, empty if the snapshot is new
+	Diff string
+	// New is set when there is no current snapshot
+	New bool
+
+	kind string
+	// stored is the snapshot as stored in the pending file, along with its metadata
+	stored string
+	meta   metadata
+	// index is the call index of inline snapshots
+	index int
+}
+
+// ReadPending returns the snapshots of the pending file, with their diff against the current snapshots.
+func ReadPending(pendingPath string) ([]PendingSnapshot, error) {
+	return readPending(pendingPath, strings.TrimSuffix(pendingPath, pendingExt))
+}
+
+// readPending returns the snapshots of the pending file written for the snapshot file target,
+// or the test file target with a snapshot extension for inline snapshots.
+func readPending(pendingPath, target string) ([]PendingSnapshot, error) {
+	data, err := storageFor(pendingPath).Read(pendingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pf, err := parseSnapshotFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, pendingPath)
+	}
+	kind := pf.header().get(pendingKey)
+
+	switch kind {
+	case pendingShared:
+	case pendingStandalone:
+		if len(pf.order) != 1 {
+			return nil, fmt.Errorf("%w: %s", errInvalidPending, pendingPath)
+		}
+	case pendingInline:
+		target = strings.TrimSuffix(target, snapsExt)
+	default:
+		return nil, fmt.Errorf("%w: %s has unknown kind %q", errInvalidPending, pendingPath, kind)
+	}
+
+	snapshots := make([]PendingSnapshot, 0, len(pf.order))
+	for _, e := range pf.order {
+		p := PendingSnapshot{
+			File:     pendingPath,
+			Target:   target,
+			ID:       strings.Trim(e.id, "[]"),
+			Snapshot: pf.snapshot(e),
+			kind:     kind,
+			stored:   pf.snapshot(e),
+			meta:     pf.metadata(e),
+		}
+		if ref := p.meta.get(blobKey); ref != "" {
+			if p.Snapshot, err = readBlob(pendingPath, ref); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.diff(e.id); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, p)
+	}
+
+	return snapshots, nil
+}
+
+// diff compares the pending snapshot with the current one.
+func (p *PendingSnapshot) diff(testID string) error {
+	var (
+		prev string
+		line int
+		err  error
+	)
+
+	switch p.kind {
+	case pendingShared:
+		prev, line, err = getPrevSnapshot(testID, p.Target)
+	case pendingStandalone:
+		line = 1
+		prev, err = getPrevStandaloneSnapshot(p.Target)
+	case pendingInline:
+		i := strings.LastIndex(p.ID, " - ")
+		if i == -1 {
+			return fmt.Errorf("%w: %s", errInvalidPending, p.File)
+		}
+		if p.index, err = strconv.Atoi(p.ID[i+3:]); err != nil {
+			return fmt.Errorf("%w: %s", errInvalidPending, p.File)
+		}
+
+		var ok bool
+		line = -1
+		if prev, ok, err = inlineSnapshotAt(p.Target, p.index); err == nil && !ok {
+			err = errSnapNotFound
+		}
+	}
+
+	if errors.Is(err, errSnapNotFound) {
+		p.New = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	p.Diff = prettyDiff(prev, p.Snapshot, p.Target, line)
+	return nil
+}
+
+// apply replaces the current snapshot with the pending one.
+func (p PendingSnapshot) apply() error {
+	switch p.kind {
+	case pendingShared:
+		var meta *snapshotMetadata
+		if len(p.meta) > 0 {
+			meta = &snapshotMetadata{entry: p.meta}
+		}
+
+		testID := "[" + p.ID + "]"
+		err := updateSnapshot(testID, p.stored, p.Target, meta, false)
+		if errors.Is(err, errSnapNotFound) {
+			return addNewSnapshot(testID, p.stored, p.Target, meta, false)
+		}
+
+		return err
+	case pendingStandalone:
+		return upsertStandaloneSnapshot(p.Snapshot, p.Target)
+	default:
+		// calls are registered once, so their index still matches after other snapshots are accepted
+		if inlineSnapshotLineMapping.AddFileIfNotExists(p.Target) {
+			if err := registerInlineCallIdx(p.Target); err != nil {
+				return err
+			}
+		}
+
+		line := inlineSnapshotLineMapping.GetLine(p.Target, p.index)
+		if line == -1 {
+			return errLocateCall
+		}
+
+		return upsertInlineSnapshot(p.Target, line, p.Snapshot)
+	}
+}
+
+// AcceptPendingSnapshot replaces the current snapshot with the pending one and removes it from the pending file,
+// so pending snapshots can be reviewed one by one.
+func AcceptPendingSnapshot(p PendingSnapshot) error {
+	if err := p.apply(); err != nil {
+		return err
+	}
+
+	return removePending(p.File, "["+p.ID+"]")
+}
+
+// removePending removes the snapshot from the pending file, removing the file if it was the last one.
+func removePending(pendingPath, testID string) error {
+	unlock, err := lockFile(pendingPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	storage := storageFor(pendingPath)
+	data, err := storage.Read(pendingPath)
+	if err != nil {
+		return err
+	}
+
+	f, err := parseSnapshotFile(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, pendingPath)
+	}
+	entries := slices.DeleteFunc(slices.Clone(f.order), func(e *indexEntry) bool { return e.id == testID })
+
+	snapshotFiles.invalidate(pendingPath)
+	if len(entries) == 0 {
+		return storage.Delete(pendingPath)
+	}
+
+	return storage.Write(pendingPath, f.render(entries))
+}
+
+// inlineSnapshotAt returns the inline snapshot of the MatchInlineSnapshot call at index, or false if it's nil.
+func inlineSnapshotAt(filename string, index int) (string, bool, error) {
+	_, astFile, err := parseFileAst(filename)
+	if err != nil {
+		return "", false, err
+	}
+
+	var (
+		i        int
+		arg      ast.Expr
+		snapshot string
+	)
+	traverseMatchInlineSnapshotAst(astFile, func(ce *ast.CallExpr) bool {
+		if i == index {
+			arg = ce.Args[2]
+			return false
+		}
+
+		i++
+		return true
+	})
+	if arg == nil {
+		return "", false, errLocateCall
+	}
+
+	call, ok := arg.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", false, nil
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false, nil
+	}
+
+	if snapshot, err = strconv.Unquote(lit.Value); err != nil {
+		return "", false, err
+	}
+
+	return snapshot, true, nil
+}
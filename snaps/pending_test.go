@@ -0,0 +1,259 @@
+package snaps
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+const pendingFilename = "pending_test.snap"
+
+func TestPendingSnapshots(t *testing.T) {
+	t.Run("should write new snapshot as pending and accept it", func(t *testing.T) {
+		snapPath := setupSnapshot(t, pendingFilename, false, "pending")
+		pendingPath := snapPath + pendingExt
+		t.Cleanup(func() { os.Remove(pendingPath) })
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) { test.Equal(t, pendingMsg, args[0].(string)) }
+
+		MatchSnapshot(mockT, "value")
+
+		test.Equal(t, 1, testEvents.items[pending])
+		test.Equal(t, 0, testEvents.items[erred])
+		_, err := os.Stat(snapPath)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+		test.Equal(
+			t,
+			"# format: 2\n# pending: shared\n\n[mock-name - 1]\nvalue\n---\n",
+			test.GetFileContent(t, pendingPath),
+		)
+
+		files, err := PendingFiles(filepath.Dir(snapPath))
+		test.NoError(t, err)
+		test.True(t, slices.Contains(files, pendingPath))
+
+		test.NoError(t, AcceptPending(pendingPath))
+
+		test.Equal(t, "\n[mock-name - 1]\nvalue\n---\n", test.GetFileContent(t, snapPath))
+		_, err = os.Stat(pendingPath)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("should write changed snapshot as pending and reject it", func(t *testing.T) {
+		snapPath := setupSnapshot(t, pendingFilename, false)
+		pendingPath := snapPath + pendingExt
+		t.Cleanup(func() { os.Remove(pendingPath) })
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(args ...any) { test.Equal(t, addedMsg, args[0].(string)) }
+		MatchSnapshot(mockT, "value")
+
+		updateVAR = "pending"
+		mockT.MockError = func(args ...any) {
+			test.Contains(t, args[0].(string), "changed")
+			test.Contains(t, args[0].(string), pendingMsg)
+		}
+		MatchSnapshot(mockT, "changed")
+
+		test.Equal(t, "\n[mock-name - 1]\nvalue\n---\n", test.GetFileContent(t, snapPath))
+		test.Equal(
+			t,
+			"# format: 2\n# pending: shared\n\n[mock-name - 1]\nchanged\n---\n",
+			test.GetFileContent(t, pendingPath),
+		)
+
+		test.NoError(t, RejectPending(pendingPath))
+
+		test.Equal(t, "\n[mock-name - 1]\nvalue\n---\n", test.GetFileContent(t, snapPath))
+		_, err := os.Stat(pendingPath)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("should not write pending snapshots on CI", func(t *testing.T) {
+		snapPath := setupSnapshot(t, pendingFilename, true, "pending")
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) { test.Equal(t, errSnapNotFound, args[0].(error)) }
+
+		MatchSnapshot(mockT, "value")
+
+		_, err := os.Stat(snapPath + pendingExt)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("should accept pending standalone snapshot", func(t *testing.T) {
+		snapPath := setupSnapshot(t, standaloneFilename, false, "pending")
+		pendingPath := snapPath + pendingExt
+		t.Cleanup(func() { os.Remove(pendingPath) })
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) { test.Equal(t, pendingMsg, args[0].(string)) }
+
+		MatchStandaloneSnapshot(mockT, "---\nvalue")
+
+		test.Equal(
+			t,
+			"# format: 2\n# pending: standalone\n\n[mock-name - standalone]\n\\---\nvalue\n---\n",
+			test.GetFileContent(t, pendingPath),
+		)
+
+		test.NoError(t, AcceptPending(filepath.Dir(snapPath)+"/..."))
+
+		test.Equal(t, "---\nvalue", test.GetFileContent(t, snapPath))
+	})
+
+	t.Run("should accept pending inline snapshots", func(t *testing.T) {
+		dir := t.TempDir()
+		filename := filepath.Join(dir, "inline_test.go")
+		test.NoError(t, os.WriteFile(filename, []byte(`package inline
+
+func TestInline(t *testing.T) {
+	snaps.MatchInlineSnapshot(t, "first", nil)
+	snaps.MatchInlineSnapshot(t, "second", snaps.Inline("old"))
+	snaps.MatchInlineSnapshot(t, "third", snaps.Inline("third"))
+}
+`), 0o644))
+		inlineSnapshotLineMapping.AddFileIfNotExists(filename)
+		test.NoError(t, registerInlineCallIdx(filename))
+		test.NoError(t, addPendingInlineSnapshot("TestInline", filename, 4, "first"))
+		test.NoError(t, addPendingInlineSnapshot("TestInline", filename, 5, "second"))
+
+		test.NoError(t, AcceptPending(dir))
+
+		test.Equal(t, `package inline
+
+func TestInline(t *testing.T) {
+	snaps.MatchInlineSnapshot(t, "first", snaps.Inline("first"))
+	snaps.MatchInlineSnapshot(t, "second", snaps.Inline("second"))
+	snaps.MatchInlineSnapshot(t, "third", snaps.Inline("third"))
+}
+`, test.GetFileContent(t, filename))
+		_, err := os.Stat(filename + snapsExt + pendingExt)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("should return error for invalid pending file", func(t *testing.T) {
+		dir := t.TempDir()
+
+		err := AcceptPending(filepath.Join(dir, "test.snap"))
+		test.True(t, errors.Is(err, os.ErrNotExist))
+
+		pendingPath := filepath.Join(dir, "test.snap.new")
+		test.NoError(t, os.WriteFile(pendingPath, []byte("\n[TestA - 1]\nvalue\n---\n"), 0o644))
+
+		err = AcceptPending(pendingPath)
+		test.True(t, errors.Is(err, errInvalidPending))
+	})
+
+	t.Run("should not remove pending files when cleaning", func(t *testing.T) {
+		dir := t.TempDir()
+		pendingPath := filepath.Join(dir, "test.snap.new")
+		test.NoError(t, os.WriteFile(pendingPath, []byte("# pending: shared\n"), 0o644))
+		registry := map[string]map[string]int{filepath.Join(dir, "other.snap"): {}}
+
+		obsolete, _, _ := examineFiles(registry, set{}, testFilter{}, true)
+
+		test.Equal(t, 0, len(obsolete))
+		_, err := os.Stat(pendingPath)
+		test.NoError(t, err)
+	})
+}
+
+func TestReviewPendingSnapshots(t *testing.T) {
+	t.Run("should read and accept pending snapshots one by one", func(t *testing.T) {
+		dir := t.TempDir()
+		snapPath := filepath.Join(dir, "test.snap")
+		pendingPath := snapPath + pendingExt
+		test.NoError(t, os.WriteFile(snapPath, []byte("\n[TestA - 1]\nold\n---\n"), 0o644))
+		test.NoError(t, os.WriteFile(
+			pendingPath,
+			[]byte("# format: 2\n# pending: shared\n\n[TestA - 1]\nnew\n---\n\n[TestB - 1]\n#@ serializer: go\nb\n---\n"),
+			0o644,
+		))
+		t.Cleanup(func() {
+			snapshotFiles.invalidate(snapPath)
+			snapshotFiles.invalidate(pendingPath)
+		})
+
+		pending, err := ReadPending(pendingPath)
+
+		test.NoError(t, err)
+		test.Equal(t, 2, len(pending))
+		test.Equal(t, "TestA - 1", pending[0].ID)
+		test.Equal(t, snapPath, pending[0].Target)
+		test.Equal(t, "new", pending[0].Snapshot)
+		test.False(t, pending[0].New)
+		test.Equal(t, prettyDiff("old", "new", snapPath, 2), pending[0].Diff)
+		test.Equal(t, "TestB - 1", pending[1].ID)
+		test.True(t, pending[1].New)
+		test.Equal(t, "", pending[1].Diff)
+
+		test.NoError(t, AcceptPendingSnapshot(pending[1]))
+
+		test.Equal(
+			t,
+			"# format: 2\n\n[TestA - 1]\nold\n---\n\n[TestB - 1]\n#@ serializer: go\nb\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+		test.Equal(
+			t,
+			"# format: 2\n# pending: shared\n\n[TestA - 1]\nnew\n---\n",
+			test.GetFileContent(t, pendingPath),
+		)
+
+		test.NoError(t, AcceptPendingSnapshot(pending[0]))
+
+		test.Equal(
+			t,
+			"# format: 2\n\n[TestA - 1]\nnew\n---\n\n[TestB - 1]\n#@ serializer: go\nb\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+		_, err = os.Stat(pendingPath)
+		test.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("should accept inline snapshots after the test file changed", func(t *testing.T) {
+		dir := t.TempDir()
+		filename := filepath.Join(dir, "inline_test.go")
+		test.NoError(t, os.WriteFile(filename, []byte(`package inline
+
+func TestInline(t *testing.T) {
+	snaps.MatchInlineSnapshot(t, "first", nil)
+	snaps.MatchInlineSnapshot(t, "second", snaps.Inline("old"))
+}
+`), 0o644))
+		inlineSnapshotLineMapping.AddFileIfNotExists(filename)
+		test.NoError(t, registerInlineCallIdx(filename))
+		test.NoError(t, addPendingInlineSnapshot("TestInline", filename, 4, "multi\nline"))
+		test.NoError(t, addPendingInlineSnapshot("TestInline", filename, 5, "second"))
+
+		pending, err := ReadPending(filename + snapsExt + pendingExt)
+		test.NoError(t, err)
+		test.Equal(t, "TestInline - 0", pending[0].ID)
+		test.True(t, pending[0].New)
+		test.Equal(t, "TestInline - 1", pending[1].ID)
+		test.Equal(t, prettyDiff("old", "second", filename, -1), pending[1].Diff)
+
+		test.NoError(t, AcceptPendingSnapshot(pending[0]))
+
+		// emulate reviewing the rest of the snapshots in another process
+		inlineSnapshotLineMapping.Lock()
+		delete(inlineSnapshotLineMapping.mapping, filename)
+		inlineSnapshotLineMapping.Unlock()
+
+		pending, err = ReadPending(filename + snapsExt + pendingExt)
+		test.NoError(t, err)
+		test.Equal(t, 1, len(pending))
+		test.NoError(t, AcceptPendingSnapshot(pending[0]))
+
+		test.Equal(t, "package inline\n\nfunc TestInline(t *testing.T) {\n"+
+			"\tsnaps.MatchInlineSnapshot(t, \"first\", snaps.Inline(`multi\nline`))\n"+
+			"\tsnaps.MatchInlineSnapshot(t, \"second\", snaps.Inline(\"second\"))\n}\n",
+			test.GetFileContent(t, filename))
+	})
+}
@@ -0,0 +1,240 @@
+package snaps
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Policy determines which snapshots are created, updated and removed when running tests.
+//
+// It is set with the UPDATE_SNAPS env variable, e.g. UPDATE_SNAPS=new, or snaps.UpdatePolicy.
+type Policy string
+
+const (
+	// PolicyNever never creates, updates or removes snapshots, missing snapshots fail the test.
+	// It's the default when running on CI.
+	PolicyNever Policy = "never"
+	// PolicyNew creates missing snapshots but never overwrites existing ones, also when running on CI.
+	// It's the default when not running on CI.
+	PolicyNew Policy = "new"
+	// PolicyUpdate creates and updates snapshots and removes obsolete ones with snaps.Clean,
+	// same as UPDATE_SNAPS=true. It's ignored when running on CI.
+	PolicyUpdate Policy = "update"
+	// PolicyClean creates snapshots and removes obsolete ones with snaps.Clean, without updating existing ones.
+	// It's ignored when running on CI.
+	PolicyClean Policy = "clean"
+	// PolicyAlways creates and updates snapshots and removes obsolete ones with snaps.Clean, also when running on CI.
+	PolicyAlways Policy = "always"
+)
+
+// updatePatterns caches the compiled UPDATE_SNAPS patterns
+var updatePatterns = sync.Map{}
+
+func (p Policy) valid() bool {
+	switch p {
+	case PolicyNever, PolicyNew, PolicyUpdate, PolicyClean, PolicyAlways:
+		return true
+	}
+
+	return false
+}
+
+func (p Policy) creates() bool {
+	return p != PolicyNever
+}
+
+func (p Policy) updates() bool {
+	return p == PolicyUpdate || p == PolicyAlways
+}
+
+func (p Policy) removes() bool {
+	return p == PolicyUpdate || p == PolicyClean || p == PolicyAlways
+}
+
+// envPolicy returns the policy UPDATE_SNAPS is set to, or "" if it's not set to one.
+//
+// Boolean values are supported, with true meaning PolicyUpdate and false the default policy.
+// Test name patterns also mean PolicyUpdate, restricted to the matching tests.
+func envPolicy() Policy {
+	if p := Policy(updateVAR); p.valid() {
+		return p
+	}
+	if updatePattern() != nil {
+		return PolicyUpdate
+	}
+	if u, err := strconv.ParseBool(updateVAR); err == nil && u {
+		return PolicyUpdate
+	}
+
+	return ""
+}
+
+// updatePolicy returns the policy in effect, given the policy of the config.
+//
+// UPDATE_SNAPS=always takes precedence over the config. On CI only PolicyNever, PolicyNew
+// and PolicyAlways are respected, the rest fall back to PolicyNever, unless running a dry run.
+func updatePolicy(p Policy) Policy {
+	env := envPolicy()
+	if env == PolicyAlways {
+		return PolicyAlways
+	}
+	if !p.valid() {
+		p = env
+	}
+
+	switch p {
+	case "":
+		if isCI {
+			return PolicyNever
+		}
+		return PolicyNew
+	case PolicyUpdate, PolicyClean:
+		// dry runs write nothing, so they are respected on CI
+		if isCI && !isDryRun() {
+			return PolicyNever
+		}
+	}
+
+	return p
+}
+
+// shouldUpdate determines whether snapshots of the test should be updated
+func shouldUpdate(p Policy, testName string) bool {
+	return updatePolicy(p).updates() && matchesUpdatePattern(p, testName)
+}
+
+// shouldCreate determines whether snapshots of the test should be created
+func shouldCreate(p Policy, testName string) bool {
+	return updatePolicy(p).creates() && matchesUpdatePattern(p, testName)
+}
+
+// shouldClean determines whether snaps.Clean should remove obsolete snapshots.
+//
+// Obsolete snapshots are not removed when only snapshots of tests matching a pattern are updated.
+func shouldClean() bool {
+	return updatePolicy("").removes() && updatePattern() == nil
+}
+
+// matchesUpdatePattern reports whether the test matches the UPDATE_SNAPS pattern,
+// when the policy of the config doesn't override it.
+func matchesUpdatePattern(p Policy, testName string) bool {
+	pattern := updatePattern()
+	if pattern == nil || p.valid() {
+		return true
+	}
+
+	return pattern.MatchString(testName)
+}
+
+// policyName describes the policy in effect for the summary.
+func policyName() string {
+	if updateVAR == "pending" && !isCI {
+		return "pending"
+	}
+
+	p := string(updatePolicy(""))
+	if pattern := updatePattern(); pattern != nil && !isCI {
+		p += " (" + pattern.String() + ")"
+	}
+
+	return p
+}
+
+// checkUpdate returns an error if the update value, of UPDATE_SNAPS or -snaps.update, is neither
+// a policy, a boolean nor a valid test name pattern. Values looking like a misspelled policy e.g. "updte"
+// are rejected too, instead of becoming patterns that match no tests.
+func checkUpdate(s string) error {
+	if s == "" || s == "pending" || Policy(s).valid() {
+		return nil
+	}
+	if _, err := strconv.ParseBool(s); err == nil {
+		return nil
+	}
+
+	if policy, ok := misspelledPolicy(s); ok {
+		return fmt.Errorf(
+			"invalid update policy %q, did you mean %q? Patterns of tests named like it can be anchored e.g. %q",
+			s, policy, "^"+s+"$",
+		)
+	}
+	if _, err := regexp.Compile(s); err != nil {
+		return fmt.Errorf("invalid update pattern: %w", err)
+	}
+
+	return nil
+}
+
+// misspelledPolicy returns the policy s is a misspelling of, i.e. s is a lowercase word within
+// one edit of a policy name, or two edits of the longer ones.
+func misspelledPolicy(s string) (string, bool) {
+	if strings.ContainsFunc(s, func(r rune) bool { return r < 'a' || r > 'z' }) {
+		return "", false
+	}
+
+	for _, name := range []string{
+		string(PolicyNever), string(PolicyNew), string(PolicyUpdate), string(PolicyClean),
+		string(PolicyAlways), "pending", "true", "false",
+	} {
+		maxEdits := 1
+		if len(name) >= 6 {
+			maxEdits = 2
+		}
+		if editDistance(s, name) <= maxEdits {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+
+	return prev[len(b)]
+}
+
+// updatePattern returns the regex UPDATE_SNAPS is set to, when only snapshots of
+// tests with names matching it should be created or updated e.g. UPDATE_SNAPS=TestAPI/users/.*
+//
+// Invalid values match no tests, checkUpdate reports them.
+func updatePattern() *regexp.Regexp {
+	if updateVAR == "" || updateVAR == "pending" || Policy(updateVAR).valid() {
+		return nil
+	}
+	if _, err := strconv.ParseBool(updateVAR); err == nil {
+		return nil
+	}
+
+	if pattern, ok := updatePatterns.Load(updateVAR); ok {
+		return pattern.(*regexp.Regexp)
+	}
+
+	pattern, err := regexp.Compile(updateVAR)
+	if err != nil || checkUpdate(updateVAR) != nil {
+		// a pattern matching nothing
+		pattern = regexp.MustCompile(`$.^`)
+	}
+	updatePatterns.Store(updateVAR, pattern)
+
+	return pattern
+}
@@ -0,0 +1,107 @@
+package snaps
+
+import (
+	"testing"
+
+	"github.com/gkampitakis/ciinfo"
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+func TestUpdatePolicy(t *testing.T) {
+	t.Cleanup(func() {
+		updateVAR = ""
+		isCI = ciinfo.IsCI
+	})
+
+	for _, tc := range []struct {
+		env                    string
+		config                 Policy
+		ci                     bool
+		expected               Policy
+		create, update, remove bool
+	}{
+		{env: "", expected: PolicyNew, create: true},
+		{env: "", ci: true, expected: PolicyNever},
+		{env: "false", expected: PolicyNew, create: true},
+		{env: "true", expected: PolicyUpdate, create: true, update: true, remove: true},
+		{env: "true", ci: true, expected: PolicyNever},
+		{env: "never", expected: PolicyNever},
+		{env: "new", ci: true, expected: PolicyNew, create: true},
+		{env: "update", expected: PolicyUpdate, create: true, update: true, remove: true},
+		{env: "update", ci: true, expected: PolicyNever},
+		{env: "clean", expected: PolicyClean, create: true, remove: true},
+		{env: "clean", ci: true, expected: PolicyNever},
+		{env: "always", ci: true, expected: PolicyAlways, create: true, update: true, remove: true},
+		{env: "pending", expected: PolicyNew, create: true},
+		{env: "TestMock", expected: PolicyUpdate, create: true, update: true},
+		{env: "true", config: PolicyNever, expected: PolicyNever, remove: true},
+		{env: "", config: PolicyUpdate, ci: true, expected: PolicyNever},
+		{env: "", config: PolicyNew, ci: true, expected: PolicyNew, create: true},
+		{env: "always", config: PolicyNever, expected: PolicyAlways, create: true, update: true, remove: true},
+		{env: "", config: "invalid", expected: PolicyNew, create: true},
+	} {
+		t.Run(tc.env+"/"+string(tc.config), func(t *testing.T) {
+			updateVAR = tc.env
+			isCI = tc.ci
+
+			test.Equal(t, tc.expected, updatePolicy(tc.config))
+			test.Equal(t, tc.create, shouldCreate(tc.config, "TestMock"))
+			test.Equal(t, tc.update, shouldUpdate(tc.config, "TestMock"))
+			// snaps.Clean only respects UPDATE_SNAPS
+			test.Equal(t, tc.remove, shouldClean())
+		})
+	}
+}
+
+func TestPolicyName(t *testing.T) {
+	t.Cleanup(func() {
+		updateVAR = ""
+		isCI = ciinfo.IsCI
+	})
+	isCI = false
+
+	updateVAR = ""
+	test.Equal(t, "new", policyName())
+
+	updateVAR = "clean"
+	test.Equal(t, "clean", policyName())
+
+	updateVAR = "pending"
+	test.Equal(t, "pending", policyName())
+
+	updateVAR = "TestAPI/users/.*"
+	test.Equal(t, "update (TestAPI/users/.*)", policyName())
+
+	isCI = true
+	test.Equal(t, "never", policyName())
+}
+
+func TestCheckUpdate(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		err   string
+	}{
+		{value: ""},
+		{value: "true"},
+		{value: "0"},
+		{value: "update"},
+		{value: "pending"},
+		{value: "TestAPI/users/.*"},
+		{value: "fast"},
+		{value: "^updte$"},
+		{value: "updte", err: `invalid update policy "updte", did you mean "update"?`},
+		{value: "udpate", err: `invalid update policy "udpate", did you mean "update"?`},
+		{value: "cleen", err: `invalid update policy "cleen", did you mean "clean"?`},
+		{value: "Test[", err: "invalid update pattern: error parsing regexp"},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			err := checkUpdate(tc.value)
+			if tc.err == "" {
+				test.NoError(t, err)
+				return
+			}
+
+			test.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
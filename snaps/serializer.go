@@ -0,0 +1,653 @@
+package snaps
+
+import (
+	"cmp"
+	"maps"
+	"math/big"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/kr/pretty"
+)
+
+const (
+	serializerIndent = "    "
+
+	// snaps struct tag values, e.g. `snaps:"-"`
+	tagName  = "snaps"
+	tagOmit  = "-"
+	tagAny   = "any"
+	tagType  = "type"
+	anyValue = "<Any value>"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	bigIntType   = reflect.TypeOf(big.Int{})
+	netIPType    = reflect.TypeOf(net.IP{})
+	byteType     = reflect.TypeOf(byte(0))
+
+	// taggedTypes caches whether a type declares snaps struct tags at any depth
+	taggedTypes = sync.Map{}
+	// dynamicTypes caches whether a type holds interfaces at any depth
+	dynamicTypes = sync.Map{}
+
+	typeSerializersRegistry = &syncTypeSerializers{
+		serializers: make(map[reflect.Type]func(any) string),
+		RWMutex:     sync.RWMutex{},
+	}
+)
+
+type syncTypeSerializers struct {
+	serializers map[reflect.Type]func(any) string
+	sync.RWMutex
+}
+
+func (s *syncTypeSerializers) register(t reflect.Type, fn func(any) string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.serializers[t] = fn
+}
+
+func (s *syncTypeSerializers) get() map[reflect.Type]func(any) string {
+	s.RLock()
+	defer s.RUnlock()
+
+	return maps.Clone(s.serializers)
+}
+
+/*
+RegisterSerializer registers a serializer for values of type T used by snaps.GoSerializer.
+
+Whenever the Go value serializer meets a value of type T, at any depth, it uses the registered
+function instead of the default rendering.
+
+	snaps.RegisterSerializer(func(d decimal.Decimal) string {
+		return d.String()
+	})
+
+If T is an interface type, the serializer is used for every value implementing it.
+
+Serializers registered on a Config with snaps.TypeSerializer take precedence.
+*/
+func RegisterSerializer[T any](fn func(T) string) {
+	typeSerializersRegistry.register(reflect.TypeFor[T](), wrapTypeSerializer(fn))
+}
+
+func wrapTypeSerializer[T any](fn func(T) string) func(any) string {
+	return func(v any) string {
+		return fn(v.(T))
+	}
+}
+
+type GoSerializerConfig struct {
+	// HideUnexported omits unexported struct fields from the snapshot
+	// Default: false
+	HideUnexported bool
+	// HideZero omits struct fields holding the zero value of their type
+	// Default: false
+	HideZero bool
+}
+
+// serialize converts the received value to a string following the configured serializer
+// and applies the configured normalizers.
+func (c *Config) serialize(v any) string {
+	s := c.serializeValue(v)
+
+	for _, n := range c.normalizers {
+		s = n.Normalize(s)
+	}
+
+	return s
+}
+
+func (c *Config) serializeValue(v any) string {
+	if c.serializer != nil {
+		return c.serializer(v)
+	}
+
+	if c.goSerializer != nil {
+		return serializeValue(v, c.goSerializer, c.serializersByType())
+	}
+
+	// kr/pretty can't honour struct tags, so values declaring them use the Go value serializer
+	if hasSnapsTagsValue(reflect.ValueOf(v), map[visit]struct{}{}) {
+		return serializeValue(v, &GoSerializerConfig{}, c.serializersByType())
+	}
+
+	return pretty.Sprint(v)
+}
+
+// serializersByType merges the global registered type serializers with the Config ones.
+func (c *Config) serializersByType() map[reflect.Type]func(any) string {
+	types := typeSerializersRegistry.get()
+	maps.Copy(types, c.typeSerializers)
+
+	return types
+}
+
+// valueSerializer is go-snaps' own deterministic Go value serializer.
+//
+// The output is Go-like syntax, similar to kr/pretty, but it never prints memory addresses,
+// sorts map keys and renders well known types e.g. time.Time in a readable form.
+type valueSerializer struct {
+	config *GoSerializerConfig
+	// types holds serializers for specific types, see RegisterSerializer
+	types map[reflect.Type]func(any) string
+	// interfaces holds the interface types with a registered serializer in deterministic order
+	interfaces []reflect.Type
+	// visiting holds the pointers, maps and slices currently being serialized, used for detecting cycles
+	visiting map[visit]struct{}
+	s        strings.Builder
+}
+
+// visit identifies a value referenced by a pointer, map or slice.
+//
+// The type is part of the key, as a pointer to the first field of a struct has the same
+// address as the struct, and so is the length, as slices can share the same backing array.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func serializeValue(
+	v any,
+	config *GoSerializerConfig,
+	types map[reflect.Type]func(any) string,
+) string {
+	if v == nil {
+		return "nil"
+	}
+
+	// strings are printed as is, same as kr/pretty.Sprint
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	vs := &valueSerializer{
+		config:   config,
+		types:    types,
+		visiting: make(map[visit]struct{}),
+	}
+	for t := range types {
+		if t.Kind() == reflect.Interface {
+			vs.interfaces = append(vs.interfaces, t)
+		}
+	}
+	slices.SortFunc(vs.interfaces, func(a, b reflect.Type) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	// make the root addressable, so unexported fields can be accessed
+	rv := reflect.New(reflect.TypeOf(v)).Elem()
+	rv.Set(reflect.ValueOf(v))
+
+	vs.write(rv, 0, true)
+
+	return vs.s.String()
+}
+
+func (vs *valueSerializer) indent(depth int) {
+	vs.s.WriteString(strings.Repeat(serializerIndent, depth))
+}
+
+// write serializes v, typed determines if the type of scalar values should be printed
+// e.g. when the static type is not known from the parent container.
+func (vs *valueSerializer) write(v reflect.Value, depth int, typed bool) {
+	if !v.IsValid() {
+		vs.s.WriteString("nil")
+		return
+	}
+
+	if vs.writeRegisteredType(v, depth) || vs.writeKnownType(v) {
+		return
+	}
+
+	t := v.Type()
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			vs.s.WriteString("nil")
+			return
+		}
+		vs.write(v.Elem(), depth, true)
+	case reflect.Bool:
+		vs.writeScalar(t, strconv.FormatBool(v.Bool()), typed && t.Name() != "bool")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		vs.writeScalar(t, strconv.FormatInt(v.Int(), 10), typed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		vs.writeScalar(t, strconv.FormatUint(v.Uint(), 10), typed)
+	case reflect.Float32, reflect.Float64:
+		vs.writeScalar(t, strconv.FormatFloat(v.Float(), 'g', -1, t.Bits()), typed)
+	case reflect.Complex64, reflect.Complex128:
+		vs.writeScalar(t, strconv.FormatComplex(v.Complex(), 'g', -1, t.Bits()), typed)
+	case reflect.String:
+		vs.writeScalar(t, strconv.Quote(v.String()), typed && t.Name() != "string")
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			vs.s.WriteString("(" + t.String() + ")(nil)")
+			return
+		}
+		vs.s.WriteString(t.String() + "{...}")
+	case reflect.Pointer:
+		vs.writePointer(v, depth)
+	case reflect.Map:
+		vs.writeMap(v, depth)
+	case reflect.Slice, reflect.Array:
+		vs.writeList(v, depth)
+	case reflect.Struct:
+		vs.writeStruct(v, depth)
+	}
+}
+
+func (vs *valueSerializer) writeScalar(t reflect.Type, value string, typed bool) {
+	if !typed {
+		vs.s.WriteString(value)
+		return
+	}
+
+	vs.s.WriteString(t.String() + "(" + value + ")")
+}
+
+// writeRegisteredType renders v with the serializer registered for its type, if any.
+func (vs *valueSerializer) writeRegisteredType(v reflect.Value, depth int) bool {
+	if len(vs.types) == 0 || v.Kind() == reflect.Interface {
+		return false
+	}
+
+	fn, ok := vs.types[v.Type()]
+	if !ok {
+		for _, t := range vs.interfaces {
+			if v.Type().Implements(t) {
+				fn = vs.types[t]
+				ok = true
+				break
+			}
+		}
+	}
+	if !ok {
+		return false
+	}
+
+	// align multiline values with the current indentation
+	vs.s.WriteString(
+		strings.ReplaceAll(fn(v.Interface()), "\n", "\n"+strings.Repeat(serializerIndent, depth)),
+	)
+	return true
+}
+
+// writeKnownType renders types whose internal representation is not meaningful in a snapshot.
+func (vs *valueSerializer) writeKnownType(v reflect.Value) bool {
+	var value string
+
+	switch v.Type() {
+	case timeType:
+		value = v.Interface().(time.Time).Format(time.RFC3339Nano)
+	case durationType:
+		value = v.Interface().(time.Duration).String()
+	case bigIntType:
+		b := v.Interface().(big.Int)
+		value = b.String()
+	case netIPType:
+		value = v.Interface().(net.IP).String()
+	default:
+		return false
+	}
+
+	vs.writeScalar(v.Type(), value, true)
+	return true
+}
+
+func (vs *valueSerializer) enter(key visit) bool {
+	if _, exists := vs.visiting[key]; exists {
+		return false
+	}
+	vs.visiting[key] = struct{}{}
+
+	return true
+}
+
+func (vs *valueSerializer) leave(key visit) {
+	delete(vs.visiting, key)
+}
+
+func (vs *valueSerializer) writePointer(v reflect.Value, depth int) {
+	t := v.Type()
+	if v.IsNil() {
+		vs.s.WriteString("(" + t.String() + ")(nil)")
+		return
+	}
+
+	key := visit{ptr: v.Pointer(), typ: t}
+	if !vs.enter(key) {
+		vs.s.WriteString("<cycle " + t.String() + ">")
+		return
+	}
+	defer vs.leave(key)
+
+	vs.s.WriteByte('&')
+	vs.write(v.Elem(), depth, true)
+}
+
+func (vs *valueSerializer) writeMap(v reflect.Value, depth int) {
+	t := v.Type()
+	if v.IsNil() {
+		vs.s.WriteString(t.String() + "(nil)")
+		return
+	}
+
+	key := visit{ptr: v.Pointer(), typ: t}
+	if !vs.enter(key) {
+		vs.s.WriteString("<cycle " + t.String() + ">")
+		return
+	}
+	defer vs.leave(key)
+
+	vs.s.WriteString(t.String() + "{")
+	if v.Len() == 0 {
+		vs.s.WriteByte('}')
+		return
+	}
+	vs.s.WriteByte('\n')
+
+	keys := v.MapKeys()
+	slices.SortFunc(keys, compareMapKeys)
+
+	typedValues := t.Elem().Kind() == reflect.Interface
+	for _, k := range keys {
+		vs.indent(depth + 1)
+		vs.write(k, depth+1, t.Key().Kind() == reflect.Interface)
+		vs.s.WriteString(": ")
+		vs.write(v.MapIndex(k), depth+1, typedValues)
+		vs.s.WriteString(",\n")
+	}
+
+	vs.indent(depth)
+	vs.s.WriteByte('}')
+}
+
+func (vs *valueSerializer) writeList(v reflect.Value, depth int) {
+	t := v.Type()
+
+	if v.Kind() == reflect.Slice {
+		if v.IsNil() {
+			vs.s.WriteString(t.String() + "(nil)")
+			return
+		}
+
+		if t.Elem() == byteType && t.Name() == "" {
+			vs.s.WriteString("[]byte(" + strconv.Quote(string(v.Bytes())) + ")")
+			return
+		}
+
+		// slices can contain themselves through interface values e.g. s[0] = s
+		if v.Len() > 0 {
+			key := visit{ptr: v.Pointer(), typ: t, len: v.Len()}
+			if !vs.enter(key) {
+				vs.s.WriteString("<cycle " + t.String() + ">")
+				return
+			}
+			defer vs.leave(key)
+		}
+	}
+
+	vs.s.WriteString(t.String() + "{")
+	if v.Len() == 0 {
+		vs.s.WriteByte('}')
+		return
+	}
+	vs.s.WriteByte('\n')
+
+	typedValues := t.Elem().Kind() == reflect.Interface
+	for i := 0; i < v.Len(); i++ {
+		vs.indent(depth + 1)
+		vs.write(v.Index(i), depth+1, typedValues)
+		vs.s.WriteString(",\n")
+	}
+
+	vs.indent(depth)
+	vs.s.WriteByte('}')
+}
+
+func (vs *valueSerializer) writeStruct(v reflect.Value, depth int) {
+	t := v.Type()
+
+	// copy to an addressable value, so unexported fields can be accessed
+	if !v.CanAddr() {
+		c := reflect.New(t).Elem()
+		c.Set(v)
+		v = c
+	}
+
+	vs.s.WriteString(t.String() + "{")
+
+	written := false
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		f := v.Field(i)
+		tag := sf.Tag.Get(tagName)
+
+		if tag == tagOmit {
+			continue
+		}
+
+		if !sf.IsExported() {
+			if vs.config.HideUnexported {
+				continue
+			}
+
+			f = reflect.NewAt(sf.Type, unsafe.Pointer(f.UnsafeAddr())).Elem()
+		}
+
+		placeholder := tagPlaceholder(tag, f)
+		if placeholder == "" && vs.config.HideZero && f.IsZero() {
+			continue
+		}
+
+		if !written {
+			vs.s.WriteByte('\n')
+			written = true
+		}
+
+		vs.indent(depth + 1)
+		vs.s.WriteString(sf.Name + ": ")
+		if placeholder != "" {
+			vs.s.WriteString(placeholder)
+		} else {
+			vs.write(f, depth+1, sf.Type.Kind() == reflect.Interface)
+		}
+		vs.s.WriteString(",\n")
+	}
+
+	if written {
+		vs.indent(depth)
+	}
+	vs.s.WriteByte('}')
+}
+
+// tagPlaceholder returns the placeholder for a struct field with `snaps:"any"` or `snaps:"type"` tag,
+// similar to match.Any and match.Type.
+func tagPlaceholder(tag string, f reflect.Value) string {
+	switch tag {
+	case tagAny:
+		return anyValue
+	case tagType:
+		t := f.Type()
+		if f.Kind() == reflect.Interface && !f.IsNil() {
+			t = f.Elem().Type()
+		}
+
+		return "<Type:" + t.String() + ">"
+	default:
+		return ""
+	}
+}
+
+// hasSnapsTags reports whether t declares snaps struct tags at any depth.
+func hasSnapsTags(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	if v, ok := taggedTypes.Load(t); ok {
+		return v.(bool)
+	}
+
+	tagged := typeReaches(t, func(t reflect.Type) bool {
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if _, ok := t.Field(i).Tag.Lookup(tagName); ok {
+				return true
+			}
+		}
+
+		return false
+	}, map[reflect.Type]struct{}{})
+	taggedTypes.Store(t, tagged)
+
+	return tagged
+}
+
+// hasInterfaces reports whether t holds interfaces at any depth, whose values are only known at runtime.
+func hasInterfaces(t reflect.Type) bool {
+	if v, ok := dynamicTypes.Load(t); ok {
+		return v.(bool)
+	}
+
+	dynamic := typeReaches(t, func(t reflect.Type) bool {
+		return t.Kind() == reflect.Interface
+	}, map[reflect.Type]struct{}{})
+	dynamicTypes.Store(t, dynamic)
+
+	return dynamic
+}
+
+// typeReaches reports whether t, or any type t is composed of, satisfies found.
+func typeReaches(t reflect.Type, found func(reflect.Type) bool, visited map[reflect.Type]struct{}) bool {
+	if _, ok := visited[t]; ok {
+		return false
+	}
+	visited[t] = struct{}{}
+
+	if found(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return typeReaches(t.Elem(), found, visited)
+	case reflect.Map:
+		return typeReaches(t.Key(), found, visited) || typeReaches(t.Elem(), found, visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if typeReaches(t.Field(i).Type, found, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasSnapsTagsValue reports whether v declares snaps struct tags at any depth,
+// including the values held by interfaces e.g. a tagged struct inside []any.
+func hasSnapsTagsValue(v reflect.Value, visiting map[visit]struct{}) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if hasSnapsTags(v.Type()) {
+		return true
+	}
+	if !hasInterfaces(v.Type()) {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		key := visit{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		if _, ok := visiting[key]; ok {
+			return false
+		}
+		visiting[key] = struct{}{}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return hasSnapsTagsValue(v.Elem(), visiting)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if hasSnapsTagsValue(v.Index(i), visiting) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if hasSnapsTagsValue(iter.Key(), visiting) || hasSnapsTagsValue(iter.Value(), visiting) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if hasSnapsTagsValue(v.Field(i), visiting) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// compareMapKeys orders map keys deterministically, numbers and strings are compared by value
+// and every other key by its serialized form.
+func compareMapKeys(a, b reflect.Value) int {
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+
+	if a.IsValid() && b.IsValid() && a.Kind() == b.Kind() {
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(a.Int(), b.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cmp.Compare(a.Uint(), b.Uint())
+		case reflect.Float32, reflect.Float64:
+			return cmp.Compare(a.Float(), b.Float())
+		case reflect.String:
+			return strings.Compare(a.String(), b.String())
+		}
+	}
+
+	return strings.Compare(mapKeyString(a), mapKeyString(b))
+}
+
+func mapKeyString(v reflect.Value) string {
+	vs := &valueSerializer{
+		config:   &GoSerializerConfig{},
+		visiting: make(map[visit]struct{}),
+	}
+	vs.write(v, 0, true)
+
+	return vs.s.String()
+}
@@ -0,0 +1,380 @@
+package snaps
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+type serializerUser struct {
+	Name     string
+	Age      int
+	Tags     []string
+	Meta     map[string]any
+	Created  time.Time
+	Timeout  time.Duration
+	Balance  *big.Int
+	IP       net.IP
+	Callback func()
+	password string
+}
+
+type serializerNode struct {
+	Value int
+	Next  *serializerNode
+}
+
+func TestSerializeValue(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	t.Run("should serialize scalars", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			input    any
+			expected string
+		}{
+			{name: "nil", input: nil, expected: "nil"},
+			{name: "string", input: "hello world", expected: "hello world"},
+			{name: "int", input: 10, expected: "int(10)"},
+			{name: "uint8", input: uint8(10), expected: "uint8(10)"},
+			{name: "float32", input: float32(10.4), expected: "float32(10.4)"},
+			{name: "float64", input: 0.1, expected: "float64(0.1)"},
+			{name: "complex", input: complex(1, 2), expected: "complex128((1+2i))"},
+			{name: "bool", input: true, expected: "true"},
+			{name: "bytes", input: []byte("hello"), expected: `[]byte("hello")`},
+			{name: "time", input: created, expected: "time.Time(2024-01-02T03:04:05.000000006Z)"},
+			{name: "duration", input: 1500 * time.Millisecond, expected: "time.Duration(1.5s)"},
+			{name: "big int", input: big.NewInt(42), expected: "&big.Int(42)"},
+			{name: "ip", input: net.ParseIP("127.0.0.1"), expected: "net.IP(127.0.0.1)"},
+			{name: "func", input: func() {}, expected: "func(){...}"},
+			{name: "nil func", input: (func())(nil), expected: "(func())(nil)"},
+			{name: "chan", input: make(chan int), expected: "chan int{...}"},
+			{name: "nil pointer", input: (*int)(nil), expected: "(*int)(nil)"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				test.Equal(t, tc.expected, serializeValue(tc.input, &GoSerializerConfig{}, nil))
+			})
+		}
+	})
+
+	t.Run("should sort map keys", func(t *testing.T) {
+		input := map[any]int{"b": 1, "a": 2, 10: 3, 2: 4}
+
+		test.Equal(
+			t,
+			"map[interface {}]int{\n    \"a\": 2,\n    \"b\": 1,\n    int(2): 4,\n    int(10): 3,\n}",
+			serializeValue(input, &GoSerializerConfig{}, nil),
+		)
+	})
+
+	t.Run("should be deterministic", func(t *testing.T) {
+		input := map[int]string{}
+		for i := 0; i < 100; i++ {
+			input[i] = "value"
+		}
+
+		expected := serializeValue(input, &GoSerializerConfig{}, nil)
+		for i := 0; i < 10; i++ {
+			test.Equal(t, expected, serializeValue(input, &GoSerializerConfig{}, nil))
+		}
+	})
+
+	t.Run("should serialize structs", func(t *testing.T) {
+		u := serializerUser{
+			Name:     "mock-user",
+			Age:      10,
+			Tags:     []string{"a", "b"},
+			Meta:     map[string]any{"key": 1, "other": "value", "nested": []any{true, nil}},
+			Created:  created,
+			Timeout:  time.Second,
+			Balance:  big.NewInt(100),
+			IP:       net.ParseIP("10.0.0.1"),
+			Callback: func() {},
+			password: "secret",
+		}
+
+		test.Equal(t, `snaps.serializerUser{
+    Name: "mock-user",
+    Age: 10,
+    Tags: []string{
+        "a",
+        "b",
+    },
+    Meta: map[string]interface {}{
+        "key": int(1),
+        "nested": []interface {}{
+            true,
+            nil,
+        },
+        "other": "value",
+    },
+    Created: time.Time(2024-01-02T03:04:05.000000006Z),
+    Timeout: time.Duration(1s),
+    Balance: &big.Int(100),
+    IP: net.IP(10.0.0.1),
+    Callback: func(){...},
+    password: "secret",
+}`, serializeValue(u, &GoSerializerConfig{}, nil))
+	})
+
+	t.Run("should hide unexported and zero fields", func(t *testing.T) {
+		u := &serializerUser{
+			Name:     "mock-user",
+			password: "secret",
+		}
+
+		test.Equal(t, `&snaps.serializerUser{
+    Name: "mock-user",
+}`, serializeValue(u, &GoSerializerConfig{HideUnexported: true, HideZero: true}, nil))
+	})
+
+	t.Run("should detect cycles", func(t *testing.T) {
+		n := &serializerNode{Value: 1}
+		n.Next = &serializerNode{Value: 2, Next: n}
+
+		test.Equal(t, `&snaps.serializerNode{
+    Value: 1,
+    Next: &snaps.serializerNode{
+        Value: 2,
+        Next: <cycle *snaps.serializerNode>,
+    },
+}`, serializeValue(n, &GoSerializerConfig{}, nil))
+	})
+
+	t.Run("should detect cycles of slices", func(t *testing.T) {
+		s := []any{nil}
+		s[0] = s
+
+		test.Equal(t, `[]interface {}{
+    <cycle []interface {}>,
+}`, serializeValue(s, &GoSerializerConfig{}, nil))
+	})
+
+	t.Run("should detect cycles through maps", func(t *testing.T) {
+		m := map[string]any{}
+		m["self"] = m
+
+		test.Equal(t, `map[string]interface {}{
+    "self": <cycle map[string]interface {}>,
+}`, serializeValue(m, &GoSerializerConfig{}, nil))
+	})
+
+	t.Run("should not report pointers to the first field as cycles", func(t *testing.T) {
+		type inner struct{ Value int }
+		type outer struct {
+			In inner
+			P  *inner
+		}
+		o := &outer{In: inner{Value: 1}}
+		o.P = &o.In
+
+		test.Equal(t, `&snaps.outer{
+    In: snaps.inner{
+        Value: 1,
+    },
+    P: &snaps.inner{
+        Value: 1,
+    },
+}`, serializeValue(o, &GoSerializerConfig{}, nil))
+	})
+
+	t.Run("should not report shared pointers as cycles", func(t *testing.T) {
+		shared := &serializerNode{Value: 1}
+
+		test.Equal(t, `[]*snaps.serializerNode{
+    &snaps.serializerNode{
+        Value: 1,
+        Next: (*snaps.serializerNode)(nil),
+    },
+    &snaps.serializerNode{
+        Value: 1,
+        Next: (*snaps.serializerNode)(nil),
+    },
+}`, serializeValue([]*serializerNode{shared, shared}, &GoSerializerConfig{}, nil))
+	})
+}
+
+type serializerID [2]byte
+
+func (id serializerID) String() string {
+	return fmt.Sprintf("id-%x", id[:])
+}
+
+type serializerOrder struct {
+	ID     serializerID
+	Amount *big.Int
+	Items  []serializerID
+	Status fmt.Stringer
+}
+
+func TestTypeSerializers(t *testing.T) {
+	order := serializerOrder{
+		ID:     serializerID{1, 2},
+		Amount: big.NewInt(10),
+		Items:  []serializerID{{3, 4}},
+		Status: serializerID{5, 6},
+	}
+
+	t.Run("should use registered serializer at any depth", func(t *testing.T) {
+		RegisterSerializer(func(id serializerID) string {
+			return id.String()
+		})
+		t.Cleanup(func() {
+			delete(typeSerializersRegistry.serializers, reflect.TypeFor[serializerID]())
+		})
+
+		c := WithConfig(GoSerializer(GoSerializerConfig{}))
+
+		test.Equal(t, `snaps.serializerOrder{
+    ID: id-0102,
+    Amount: &big.Int(10),
+    Items: []snaps.serializerID{
+        id-0304,
+    },
+    Status: id-0506,
+}`, c.serialize(order))
+	})
+
+	t.Run("config serializer should take precedence over registered one", func(t *testing.T) {
+		RegisterSerializer(func(id serializerID) string {
+			return "global"
+		})
+		t.Cleanup(func() {
+			delete(typeSerializersRegistry.serializers, reflect.TypeFor[serializerID]())
+		})
+
+		c := WithConfig(
+			GoSerializer(GoSerializerConfig{}),
+			TypeSerializer(func(id serializerID) string {
+				return "config"
+			}),
+			TypeSerializer(func(b big.Int) string {
+				return "big:\n" + b.String()
+			}),
+		)
+
+		test.Equal(t, `snaps.serializerOrder{
+    ID: config,
+    Amount: &big:
+    10,
+    Items: []snaps.serializerID{
+        config,
+    },
+    Status: config,
+}`, c.serialize(order))
+	})
+
+	t.Run("should use serializer registered for interface", func(t *testing.T) {
+		c := WithConfig(
+			GoSerializer(GoSerializerConfig{}),
+			TypeSerializer(func(s fmt.Stringer) string {
+				return "stringer:" + s.String()
+			}),
+		)
+
+		test.Equal(t, "stringer:id-0102", c.serialize(order.ID))
+	})
+
+	t.Run("should not mutate other configs", func(t *testing.T) {
+		base := WithConfig(TypeSerializer(func(id serializerID) string { return "base" }))
+		_ = WithConfig(TypeSerializer(func(b big.Int) string { return "" }))
+
+		test.Equal(t, 1, len(base.typeSerializers))
+		test.Nil(t, defaultConfig.typeSerializers)
+	})
+}
+
+type serializerTagged struct {
+	ID        string `snaps:"any"`
+	Name      string
+	CreatedAt time.Time `snaps:"type"`
+	Token     string    `snaps:"-"`
+	Value     any       `snaps:"type"`
+	Next      *serializerTagged
+}
+
+func TestSerializerStructTags(t *testing.T) {
+	v := serializerTagged{
+		ID:        "8a2f",
+		Name:      "mock-name",
+		CreatedAt: time.Now(),
+		Token:     "secret",
+		Value:     10,
+		Next:      &serializerTagged{Name: "mock-next"},
+	}
+	expected := `snaps.serializerTagged{
+    ID: <Any value>,
+    Name: "mock-name",
+    CreatedAt: <Type:time.Time>,
+    Value: <Type:int>,
+    Next: &snaps.serializerTagged{
+        ID: <Any value>,
+        Name: "mock-next",
+        CreatedAt: <Type:time.Time>,
+        Value: <Type:interface {}>,
+        Next: (*snaps.serializerTagged)(nil),
+    },
+}`
+
+	t.Run("should honour struct tags", func(t *testing.T) {
+		test.Equal(t, expected, serializeValue(v, &GoSerializerConfig{}, nil))
+	})
+
+	t.Run("should keep placeholders when hiding zero values", func(t *testing.T) {
+		test.Equal(t, `snaps.serializerTagged{
+    ID: <Any value>,
+    CreatedAt: <Type:time.Time>,
+    Value: <Type:interface {}>,
+}`, serializeValue(serializerTagged{}, &GoSerializerConfig{HideZero: true}, nil))
+	})
+
+	t.Run("should use go serializer by default for tagged values", func(t *testing.T) {
+		test.Equal(t, expected, defaultConfig.takeStandaloneSnapshot(v))
+		test.Equal(t, "&"+expected, defaultConfig.takeInlineSnapshot(&v))
+		test.Equal(t, "int(10)\n"+expected, defaultConfig.takeSnapshot([]any{10, v}))
+	})
+
+	t.Run("should detect tags at any depth", func(t *testing.T) {
+		test.True(t, hasSnapsTags(reflect.TypeOf(map[string][]*serializerTagged{})))
+		test.True(t, hasSnapsTags(reflect.TypeOf(struct{ Inner serializerTagged }{})))
+		test.False(t, hasSnapsTags(reflect.TypeOf(serializerNode{})))
+		test.False(t, hasSnapsTags(reflect.TypeOf(10)))
+		test.False(t, hasSnapsTags(nil))
+	})
+
+	t.Run("should detect tags of values inside interfaces", func(t *testing.T) {
+		for _, value := range []any{
+			[]any{v},
+			map[string]any{"v": v},
+			struct{ Err error }{Err: taggedError{v}},
+			&struct{ Value any }{Value: &v},
+		} {
+			test.True(t, hasSnapsTagsValue(reflect.ValueOf(value), map[visit]struct{}{}))
+			s := defaultConfig.takeStandaloneSnapshot(value)
+			test.Contains(t, s, "ID: <Any value>")
+			test.False(t, strings.Contains(s, "secret"))
+		}
+
+		test.False(t, hasSnapsTagsValue(reflect.ValueOf([]any{10, serializerNode{}}), map[visit]struct{}{}))
+		test.False(t, hasSnapsTagsValue(reflect.ValueOf(map[string]any{"v": nil}), map[visit]struct{}{}))
+	})
+
+	t.Run("should not loop on cycles through interfaces", func(t *testing.T) {
+		cycle := []any{nil}
+		cycle[0] = cycle
+
+		test.False(t, hasSnapsTagsValue(reflect.ValueOf(cycle), map[visit]struct{}{}))
+	})
+}
+
+type taggedError struct {
+	serializerTagged
+}
+
+func (taggedError) Error() string { return "tagged" }
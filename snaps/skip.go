@@ -5,7 +5,6 @@ import (
 	"go/parser"
 	"go/token"
 	"path"
-	"regexp"
 	"strings"
 
 	"github.com/gkampitakis/go-snaps/internal/colors"
@@ -55,7 +54,7 @@ func trackSkip(t testingT) {
 
 /*
 This checks if the parent test is skipped,
-or provided a 'runOnly' the testID is part of it
+or the test didn't run because of the -run and -skip flags
 
 e.g
 
@@ -66,7 +65,7 @@ e.g
 
 Then every "child" test should be skipped
 */
-func testSkipped(testID, runOnly string) bool {
+func testSkipped(testID string, filter testFilter) bool {
 	// testID form: Test.*/runName - 1
 	testName := strings.Split(testID, " - ")[0]
 
@@ -76,13 +75,12 @@ func testSkipped(testID, runOnly string) bool {
 		}
 	}
 
-	matched, _ := regexp.MatchString(runOnly, testID)
-	return !matched
+	return !filter.runs(testName)
 }
 
-func isFileSkipped(dir, filename, runOnly string) bool {
-	// When a file is skipped through CLI with -run flag we can track it
-	if runOnly == "" {
+func isFileSkipped(dir, filename string, filter testFilter) bool {
+	// When a file is skipped through CLI with -run or -skip flags we can track it
+	if filter.empty() {
 		return false
 	}
 
@@ -96,13 +94,13 @@ func isFileSkipped(dir, filename, runOnly string) bool {
 
 	for _, decls := range file.Decls {
 		funcDecl, ok := decls.(*ast.FuncDecl)
-		if !ok {
+		// only top level test functions are run, helpers and methods don't count
+		if !ok || funcDecl.Recv != nil || !strings.HasPrefix(funcDecl.Name.Name, "Test") {
 			continue
 		}
 
 		// If the TestFunction is inside the file then it's not skipped
-		matched, _ := regexp.MatchString(runOnly, funcDecl.Name.String())
-		if matched {
+		if filter.runs(funcDecl.Name.String()) {
 			return false
 		}
 	}
@@ -2,6 +2,7 @@ package snaps
 
 import (
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -97,19 +98,19 @@ func TestSkip(t *testing.T) {
 	})
 
 	t.Run("testSkipped", func(t *testing.T) {
-		t.Run("should return true if testID is not part of the 'runOnly'", func(t *testing.T) {
-			runOnly := "TestMock"
+		t.Run("should return true if testID is not part of the -run pattern", func(t *testing.T) {
+			filter := newTestFilter("TestMock", "")
 			testID := "TestSkip/should_call_Skip - 1"
 
-			received := testSkipped(testID, runOnly)
+			received := testSkipped(testID, filter)
 			test.True(t, received)
 		})
 
-		t.Run("should return false if testID is part of 'runOnly'", func(t *testing.T) {
-			runOnly := "TestMock"
+		t.Run("should return false if testID is part of the -run pattern", func(t *testing.T) {
+			filter := newTestFilter("TestMock", "")
 			testID := "TestMock/Test/should_be_not_skipped - 2"
 
-			received := testSkipped(testID, runOnly)
+			received := testSkipped(testID, filter)
 			test.False(t, received)
 		})
 
@@ -120,7 +121,7 @@ func TestSkip(t *testing.T) {
 					skippedTests = newSyncSlice()
 				})
 
-				runOnly := ""
+				filter := testFilter{}
 				mockT := test.NewMockTestingT(t)
 				mockT.MockName = func() string {
 					return "TestMock/Skip"
@@ -133,12 +134,12 @@ func TestSkip(t *testing.T) {
 				// This is for populating skippedTests.values and following the normal flow
 				SkipNow(mockT)
 
-				test.True(t, testSkipped("TestMock/Skip - 1000", runOnly))
+				test.True(t, testSkipped("TestMock/Skip - 1000", filter))
 				test.True(
 					t,
-					testSkipped("TestMock/Skip/child_should_also_be_skipped", runOnly),
+					testSkipped("TestMock/Skip/child_should_also_be_skipped", filter),
 				)
-				test.False(t, testSkipped("TestAnotherTest", runOnly))
+				test.False(t, testSkipped("TestAnotherTest", filter))
 			},
 		)
 
@@ -147,7 +148,7 @@ func TestSkip(t *testing.T) {
 				skippedTests = newSyncSlice()
 			})
 
-			runOnly := ""
+			filter := testFilter{}
 			mockT := test.NewMockTestingT(t)
 			mockT.MockName = func() string {
 				return "Test"
@@ -160,21 +161,39 @@ func TestSkip(t *testing.T) {
 			// This is for populating skippedTests.values and following the normal flow
 			SkipNow(mockT)
 
-			test.True(t, testSkipped("Test - 1", runOnly))
-			test.True(t, testSkipped("Test/child - 1", runOnly))
-			test.False(t, testSkipped("TestMock - 1", runOnly))
-			test.False(t, testSkipped("TestMock/child - 1", runOnly))
+			test.True(t, testSkipped("Test - 1", filter))
+			test.True(t, testSkipped("Test/child - 1", filter))
+			test.False(t, testSkipped("TestMock - 1", filter))
+			test.False(t, testSkipped("TestMock/child - 1", filter))
 		})
 
-		t.Run("should use regex match for runOnly", func(t *testing.T) {
-			test.False(t, testSkipped("MyTest - 1", "Test"))
-			test.True(t, testSkipped("MyTest - 1", "^Test"))
+		t.Run("should use regex match for the -run pattern", func(t *testing.T) {
+			test.False(t, testSkipped("MyTest - 1", newTestFilter("Test", "")))
+			test.True(t, testSkipped("MyTest - 1", newTestFilter("^Test", "")))
+		})
+
+		t.Run("should match each level of the test name", func(t *testing.T) {
+			filter := newTestFilter("TestA/case1", "")
+
+			test.False(t, testSkipped("TestA - 1", filter))
+			test.False(t, testSkipped("TestA/case1 - 1", filter))
+			test.False(t, testSkipped("TestA/case10/child - 1", filter))
+			test.True(t, testSkipped("TestA/case2 - 1", filter))
+			test.True(t, testSkipped("TestB/case1 - 1", filter))
+		})
+
+		t.Run("should return true if testID matches the -skip pattern", func(t *testing.T) {
+			filter := newTestFilter("", "TestSlow")
+
+			test.True(t, testSkipped("TestSlow - 1", filter))
+			test.True(t, testSkipped("TestSlow/child - after-login", filter))
+			test.False(t, testSkipped("TestFast - 1", filter))
 		})
 	})
 
 	t.Run("isFileSkipped", func(t *testing.T) {
 		t.Run("should return 'false'", func(t *testing.T) {
-			test.False(t, isFileSkipped("", "", ""))
+			test.False(t, isFileSkipped("", "", testFilter{}))
 		})
 
 		t.Run("should return 'true' if test is not included in the test file", func(t *testing.T) {
@@ -183,23 +202,68 @@ func TestSkip(t *testing.T) {
 			test.Equal(
 				t,
 				true,
-				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", "TestNonExistent"),
+				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", newTestFilter("TestNonExistent", "")),
 			)
 		})
 
 		t.Run("should return 'false' if test is included in the test file", func(t *testing.T) {
 			dir, _ := os.Getwd()
 
-			test.False(t, isFileSkipped(dir+"/__snapshots__", "skip_test.snap", "TestSkip"))
+			test.False(
+				t,
+				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", newTestFilter("TestSkip", "")),
+			)
 		})
 
-		t.Run("should use regex match for runOnly", func(t *testing.T) {
+		t.Run("should use regex match for the -run pattern", func(t *testing.T) {
 			dir, _ := os.Getwd()
 
 			test.Equal(
 				t,
 				false,
-				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", "TestSkip.*"),
+				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", newTestFilter("TestSkip.*", "")),
+			)
+		})
+
+		t.Run("should match only the top level of the -run pattern", func(t *testing.T) {
+			dir, _ := os.Getwd()
+
+			test.False(
+				t,
+				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", newTestFilter("TestSkip/testSkipped", "")),
+			)
+			test.True(
+				t,
+				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", newTestFilter("TestNonExistent/TestSkip", "")),
+			)
+		})
+
+		t.Run("should return 'true' if every test of the file matches -skip", func(t *testing.T) {
+			dir, _ := os.Getwd()
+
+			test.True(
+				t,
+				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", newTestFilter("", "^Test")),
+			)
+			test.False(
+				t,
+				isFileSkipped(dir+"/__snapshots__", "skip_test.snap", newTestFilter("", "TestSkip/testSkipped")),
+			)
+		})
+
+		t.Run("should ignore helpers and methods of the test file", func(t *testing.T) {
+			dir := t.TempDir()
+			test.NoError(t, os.WriteFile(filepath.Join(dir, "x_test.go"), []byte(
+				"package x\n\nfunc TestSlow(t *testing.T) {}\n\nfunc helper() {}\n\nfunc (s suite) TestMethod() {}\n",
+			), 0o644))
+
+			test.True(
+				t,
+				isFileSkipped(filepath.Join(dir, "__snapshots__"), "x_test.snap", newTestFilter("", "TestSlow")),
+			)
+			test.False(
+				t,
+				isFileSkipped(filepath.Join(dir, "__snapshots__"), "x_test.snap", newTestFilter("", "TestOther")),
 			)
 		})
 	})
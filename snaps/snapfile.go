@@ -0,0 +1,323 @@
+package snaps
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Snapshot files have the form
+
+	# format: 2
+	# go-snaps: v0.5.13
+
+	[TestName - 1]
+	#@ serializer: pretty
+	<snapshot>
+	---
+
+The header lines at the start of the file and the metadata lines after the test id are optional.
+Files without a header are in the legacy format.
+*/
+const (
+	headerPrefix   = "# "
+	metadataMarker = "#@"
+	metadataPrefix = metadataMarker + " "
+	formatKey      = "format"
+)
+
+// indexEntry locates a snapshot inside a snapshot file.
+type indexEntry struct {
+	id string
+	// line is the line of the test id
+	line int
+	// start is the offset after the test id line, where metadata lines start.
+	// bodyStart is the offset of the snapshot and end the offset of the end sequence line.
+	start, bodyStart, end int
+}
+
+// snapshotFile reads and writes a snapshot file, with the entries indexed by test id.
+type snapshotFile struct {
+	data []byte
+	// headerEnd is the offset where the header lines end
+	headerEnd int
+	entries   map[string]*indexEntry
+	// order keeps the entries in the order they appear in the file
+	order []*indexEntry
+	// crlf is set when the file uses CRLF line endings, which are preserved on write
+	crlf bool
+	// format is the format of the file defining how snapshots are escaped
+	format int
+	// dirty is set when there are buffered writes not yet flushed
+	dirty bool
+	// foreign is set when the file has content outside of snapshots, e.g. standalone snapshot files
+	foreign bool
+	sync.RWMutex
+}
+
+type metadataField struct {
+	key, value string
+}
+
+// metadata are ordered "key: value" lines stored in the file header or with a snapshot.
+type metadata []metadataField
+
+func (m metadata) lines(prefix string) string {
+	var b strings.Builder
+	for _, f := range m {
+		if f.value == "" {
+			continue
+		}
+
+		b.WriteString(prefix + f.key + ": " + strings.ReplaceAll(f.value, "\n", `\n`) + "\n")
+	}
+
+	return b.String()
+}
+
+// get returns the value of key or an empty string.
+func (m metadata) get(key string) string {
+	for _, f := range m {
+		if f.key == key {
+			return f.value
+		}
+	}
+
+	return ""
+}
+
+func parseMetadataLine(line []byte, prefix string) metadataField {
+	key, value, _ := strings.Cut(strings.TrimPrefix(string(line), prefix), ":")
+
+	return metadataField{key: strings.TrimSpace(key), value: strings.TrimSpace(value)}
+}
+
+// lines iterates over the lines of data, passing each line without its line ending
+// along with its offset and the offset of the next line.
+func lines(data []byte, yield func(line []byte, offset, next int) bool) {
+	for offset := 0; offset < len(data); {
+		next := len(data)
+		lineEnd := len(data)
+		if i := bytes.IndexByte(data[offset:], '\n'); i != -1 {
+			lineEnd = offset + i
+			next = lineEnd + 1
+		}
+
+		if !yield(bytes.TrimSuffix(data[offset:lineEnd], []byte{'\r'}), offset, next) {
+			return
+		}
+		offset = next
+	}
+}
+
+// parseHeader parses the header lines at the start of a snapshot file, returning them
+// with the file's format and the offset where the header ends.
+//
+// Formats other than the ones known to this version of go-snaps are rejected, as their snapshots
+// can't be read or written correctly.
+func parseHeader(data []byte) (metadata, int, int, error) {
+	var (
+		header    metadata
+		headerEnd int
+	)
+
+	lines(data, func(line []byte, _, next int) bool {
+		if !bytes.HasPrefix(line, []byte(headerPrefix)) {
+			return false
+		}
+
+		header = append(header, parseMetadataLine(line, headerPrefix))
+		headerEnd = next
+		return true
+	})
+
+	value := header.get(formatKey)
+	if value == "" {
+		return header, legacyFormat, headerEnd, nil
+	}
+
+	format, err := strconv.Atoi(value)
+	if err != nil || format != legacyFormat && format != escapedFormat {
+		return header, 0, headerEnd, fmt.Errorf("%w %q", errUnsupportedFormat, value)
+	}
+
+	return header, format, headerEnd, nil
+}
+
+func parseSnapshotFile(data []byte) (*snapshotFile, error) {
+	f := &snapshotFile{
+		data:    data,
+		entries: make(map[string]*indexEntry),
+		crlf:    usesCRLF(data),
+		RWMutex: sync.RWMutex{},
+	}
+
+	var err error
+	if _, f.format, f.headerEnd, err = parseHeader(data); err != nil {
+		return nil, err
+	}
+
+	var (
+		current *indexEntry
+		// inMetadata is set while reading the metadata lines of the current entry
+		inMetadata bool
+	)
+	lineNumber := bytes.Count(data[:f.headerEnd], []byte{'\n'})
+	lines(data[f.headerEnd:], func(line []byte, offset, next int) bool {
+		offset, next = offset+f.headerEnd, next+f.headerEnd
+		lineNumber++
+
+		if current == nil {
+			if isTestIDLine(line) {
+				current = &indexEntry{
+					id:        string(line),
+					line:      lineNumber,
+					start:     next,
+					bodyStart: next,
+				}
+				// legacy files have no metadata
+				inMetadata = f.format != legacyFormat
+				return true
+			}
+			if len(bytes.TrimSpace(line)) != 0 {
+				f.foreign = true
+			}
+			return true
+		}
+
+		if inMetadata && bytes.HasPrefix(line, []byte(metadataPrefix)) {
+			current.bodyStart = next
+			return true
+		}
+		inMetadata = false
+
+		if bytes.Equal(line, endSequenceByteSlice) {
+			current.end = offset
+			f.order = append(f.order, current)
+			// the first occurrence of a test id wins
+			if _, exists := f.entries[current.id]; !exists {
+				f.entries[current.id] = current
+			}
+			current = nil
+		}
+
+		return true
+	})
+	// a snapshot without the end sequence
+	if current != nil {
+		f.foreign = true
+	}
+
+	return f, nil
+}
+
+// isTestIDLine reports whether the line starts a snapshot. Other lines, e.g. "[section]" of an ini file,
+// are snapshot content.
+func isTestIDLine(b []byte) bool {
+	_, ok := parseTestID(b)
+	return ok
+}
+
+func (f *snapshotFile) assign(parsed *snapshotFile) {
+	f.data, f.headerEnd, f.entries, f.order = parsed.data, parsed.headerEnd, parsed.entries, parsed.order
+	f.crlf, f.format, f.foreign = parsed.crlf, parsed.format, parsed.foreign
+}
+
+// header returns the header lines of the file.
+func (f *snapshotFile) header() metadata {
+	// the header was already parsed along with the file
+	header, _, _, _ := parseHeader(f.data[:f.headerEnd])
+	return header
+}
+
+// body returns the stored snapshot of the entry, still escaped.
+func (f *snapshotFile) body(e *indexEntry) string {
+	body := f.data[e.bodyStart:e.end]
+	if f.crlf {
+		body = toLF(body)
+	}
+
+	return string(bytes.TrimSuffix(body, []byte{'\n'}))
+}
+
+// snapshot returns the snapshot of the entry.
+func (f *snapshotFile) snapshot(e *indexEntry) string {
+	return unescapeSnapshot(f.body(e), f.format)
+}
+
+// metadata returns the metadata lines of the entry.
+func (f *snapshotFile) metadata(e *indexEntry) metadata {
+	var m metadata
+	lines(f.data[e.start:e.bodyStart], func(line []byte, _, _ int) bool {
+		m = append(m, parseMetadataLine(line, metadataPrefix))
+		return true
+	})
+
+	return m
+}
+
+// render returns the file's contents with only the given entries, in the given order.
+func (f *snapshotFile) render(entries []*indexEntry) []byte {
+	var b bytes.Buffer
+	b.Write(f.data[:f.headerEnd])
+
+	for _, e := range entries {
+		b.Write(f.lineEndings([]byte("\n" + e.id + "\n")))
+		b.Write(f.data[e.start:e.end])
+		b.Write(f.lineEndings([]byte(endSequence + "\n")))
+	}
+
+	return b.Bytes()
+}
+
+// migrate converts a legacy format file to escapedFormat with the given header lines,
+// so snapshots can be escaped reversibly and stored with metadata.
+// The file is written with the next write.
+func (f *snapshotFile) migrate(header metadata) {
+	var b strings.Builder
+	b.WriteString(append(metadata{{key: formatKey, value: strconv.Itoa(escapedFormat)}}, header...).lines(headerPrefix))
+
+	for _, e := range f.order {
+		b.WriteString("\n" + e.id + "\n")
+		b.WriteString(escapeSnapshot(f.snapshot(e), escapedFormat))
+		b.WriteString("\n" + endSequence + "\n")
+	}
+
+	crlf := f.crlf
+	// the file is in escapedFormat, so it can always be parsed
+	parsed, _ := parseSnapshotFile(f.lineEndings([]byte(b.String())))
+	f.assign(parsed)
+	f.crlf = crlf
+}
+
+// block returns the metadata lines and the escaped snapshot as stored in the file.
+//
+// Legacy format files are migrated if the snapshot needs escaping or has metadata.
+func (f *snapshotFile) block(snapshot string, meta *snapshotMetadata) ([]byte, []byte) {
+	if f.format == legacyFormat && (meta != nil || needsEscaping(snapshot)) {
+		var header metadata
+		if meta != nil {
+			header = meta.header
+		}
+		f.migrate(header)
+	}
+
+	var metaLines string
+	if meta != nil {
+		metaLines = meta.entry.lines(metadataPrefix)
+	}
+
+	return f.lineEndings([]byte(metaLines)), f.lineEndings([]byte(escapeSnapshot(snapshot, f.format) + "\n"))
+}
+
+// lineEndings converts b to the line endings used by the file.
+func (f *snapshotFile) lineEndings(b []byte) []byte {
+	if f.crlf {
+		return toCRLF(b)
+	}
+
+	return b
+}
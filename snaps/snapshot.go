@@ -1,11 +1,7 @@
 package snaps
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -16,7 +12,6 @@ import (
 var (
 	testsRegistry           = newRegistry()
 	standaloneTestsRegistry = newStandaloneRegistry()
-	_m                      = sync.RWMutex{}
 	endSequenceByteSlice    = []byte(endSequence)
 )
 
@@ -34,9 +29,14 @@ func handleError(t testingT, err any) {
 // We track occurrence as in the same test we can run multiple snapshots
 // This also helps with keeping track with obsolete snaps
 // map[snap path]: map[testname]: <number of snapshots>
+//
+// Named snapshots are tracked separately
+// map[snap path]: map[testname]: <set of names>
 type syncRegistry struct {
-	running map[string]map[string]int
-	cleanup map[string]map[string]int
+	running      map[string]map[string]int
+	cleanup      map[string]map[string]int
+	runningNames map[string]map[string]set
+	cleanupNames map[string]set
 	sync.Mutex
 }
 
@@ -45,11 +45,7 @@ type syncRegistry struct {
 func (s *syncRegistry) getTestID(snapPath, testName string) string {
 	s.Lock()
 
-	if _, exists := s.running[snapPath]; !exists {
-		s.running[snapPath] = make(map[string]int)
-		s.cleanup[snapPath] = make(map[string]int)
-	}
-
+	s.init(snapPath)
 	s.running[snapPath][testName]++
 	s.cleanup[snapPath][testName]++
 	c := s.running[snapPath][testName]
@@ -58,32 +54,105 @@ func (s *syncRegistry) getTestID(snapPath, testName string) string {
 	return fmt.Sprintf("[%s - %d]", testName, c)
 }
 
+// Returns the id of a named snapshot in the snapshot
+// Form [<test-name> - <name>]
+//
+// Each name can be used only once in a test.
+func (s *syncRegistry) getNamedTestID(snapPath, testName, name string) (string, error) {
+	if !isValidName(name) {
+		return "", fmt.Errorf("%w: %q", errInvalidName, name)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.init(snapPath)
+	if _, exists := s.runningNames[snapPath][testName]; !exists {
+		s.runningNames[snapPath][testName] = make(set)
+	}
+	if s.runningNames[snapPath][testName].Has(name) {
+		return "", fmt.Errorf("%w: %q in %s", errDuplicateName, name, testName)
+	}
+
+	id := snapshotOccurrenceFMT(testName, name)
+	s.runningNames[snapPath][testName][name] = struct{}{}
+	s.cleanupNames[snapPath][id] = struct{}{}
+
+	return "[" + id + "]", nil
+}
+
+func (s *syncRegistry) init(snapPath string) {
+	if _, exists := s.running[snapPath]; !exists {
+		s.running[snapPath] = make(map[string]int)
+		s.cleanup[snapPath] = make(map[string]int)
+		s.runningNames[snapPath] = make(map[string]set)
+		s.cleanupNames[snapPath] = make(set)
+	}
+}
+
 // reset sets only the number of running registry for the given test to 0.
 func (s *syncRegistry) reset(snapPath, testName string) {
 	s.Lock()
 	s.running[snapPath][testName] = 0
+	delete(s.runningNames[snapPath], testName)
 	s.Unlock()
 }
 
 func newRegistry() *syncRegistry {
 	return &syncRegistry{
-		running: make(map[string]map[string]int),
-		cleanup: make(map[string]map[string]int),
-		Mutex:   sync.Mutex{},
+		running:      make(map[string]map[string]int),
+		cleanup:      make(map[string]map[string]int),
+		runningNames: make(map[string]map[string]set),
+		cleanupNames: make(map[string]set),
+		Mutex:        sync.Mutex{},
 	}
 }
 
+// snapshotID returns the id of the snapshot either named, if snaps.Name is configured, or by occurrence.
+func (c *Config) snapshotID(snapPath, testName string) (string, error) {
+	if c.name != "" {
+		return testsRegistry.getNamedTestID(snapPath, testName, c.name)
+	}
+
+	return testsRegistry.getTestID(snapPath, testName), nil
+}
+
+// isValidName reports whether name can be used for naming a snapshot. It must start with a letter
+// and contain only letters, digits, '_', '.' or '-'. This way named ids can't be confused with occurrences.
+func isValidName(name string) bool {
+	if name == "" || !isLetter(name[0]) {
+		return false
+	}
+
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if !isLetter(c) && (c < '0' || c > '9') && c != '_' && c != '.' && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Named standalone snapshots are tracked by their path
+// cleanupNames: set of snap paths
 type syncStandaloneRegistry struct {
-	running map[string]int
-	cleanup map[string]int
+	running      map[string]int
+	cleanup      map[string]int
+	cleanupNames set
 	sync.Mutex
 }
 
 func newStandaloneRegistry() *syncStandaloneRegistry {
 	return &syncStandaloneRegistry{
-		running: make(map[string]int),
-		cleanup: make(map[string]int),
-		Mutex:   sync.Mutex{},
+		running:      make(map[string]int),
+		cleanup:      make(map[string]int),
+		cleanupNames: make(set),
+		Mutex:        sync.Mutex{},
 	}
 }
 
@@ -98,143 +167,117 @@ func (s *syncStandaloneRegistry) getTestID(snapPath, snapPathRel string) (string
 	return fmt.Sprintf(snapPath, c), fmt.Sprintf(snapPathRel, c)
 }
 
+// registerNamed registers the path of a named standalone snapshot.
+//
+// Returns false if the path is already used in the running test.
+func (s *syncStandaloneRegistry) registerNamed(snapPath string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	s.running[snapPath]++
+	s.cleanupNames[snapPath] = struct{}{}
+
+	return s.running[snapPath] == 1
+}
+
 func (s *syncStandaloneRegistry) reset(snapPath string) {
 	s.Lock()
 	s.running[snapPath] = 0
 	s.Unlock()
 }
 
-// getPrevSnapshot scans file searching for a snapshot matching the given testID and returns
-// the snapshot with the line where is located inside the file.
-//
-// If not found returns errSnapNotFound error.
-func getPrevSnapshot(testID, snapPath string) (string, int, error) {
-	_m.RLock()
-	defer _m.RUnlock()
-
-	f, err := os.ReadFile(snapPath)
-	if err != nil {
-		return "", -1, errSnapNotFound
+// standaloneSnapshotPath returns the path of the standalone snapshot either named, if snaps.Name is configured,
+// or by occurrence.
+func (c *Config) standaloneSnapshotPath(
+	testName, snapPath, snapPathRel string,
+) (string, string, error) {
+	if c.name == "" {
+		snapPath, snapPathRel := standaloneTestsRegistry.getTestID(snapPath, snapPathRel)
+		// the storage is registered for the numbered snapshot file
+		storages.register(snapPath, c.storage)
+		return snapPath, snapPathRel, nil
 	}
 
-	lineNumber := 1
-	tid := []byte(testID)
-
-	s := snapshotScanner(bytes.NewReader(f))
-	for s.Scan() {
-		l := s.Bytes()
-		if !bytes.Equal(l, tid) {
-			lineNumber++
-			continue
-		}
-		var snapshot strings.Builder
-
-		for s.Scan() {
-			line := s.Bytes()
-
-			if bytes.Equal(line, endSequenceByteSlice) {
-				return strings.TrimSuffix(snapshot.String(), "\n"), lineNumber, nil
-			}
-			snapshot.Write(line)
-			snapshot.WriteByte('\n')
-		}
+	if !isValidName(c.name) {
+		return "", "", fmt.Errorf("%w: %q", errInvalidName, c.name)
 	}
 
-	if err := s.Err(); err != nil {
-		return "", -1, err
+	if !standaloneTestsRegistry.registerNamed(snapPath) {
+		return "", "", fmt.Errorf("%w: %q in %s", errDuplicateName, c.name, testName)
 	}
 
-	return "", -1, errSnapNotFound
+	return snapPath, snapPathRel, nil
 }
 
-func addNewSnapshot(testID, snapshot, snapPath string) error {
-	if err := os.MkdirAll(filepath.Dir(snapPath), 0o755); err != nil {
-		return err
+// getPrevSnapshot looks up the snapshot matching the given testID in the snapshot file's index
+// and returns the snapshot with the line where is located inside the file.
+//
+// If not found returns errSnapNotFound error.
+func getPrevSnapshot(testID, snapPath string) (string, int, error) {
+	f, err := snapshotFiles.load(snapPath)
+	if err != nil {
+		return "", -1, err
 	}
 
-	f, err := os.OpenFile(snapPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
-		return err
+	snapshot, line, ok := f.get(testID)
+	if !ok {
+		return "", -1, errSnapNotFound
+	}
+	if ref := f.blob(testID); ref != "" {
+		if snapshot, err = readBlob(snapPath, ref); err != nil {
+			return "", -1, err
+		}
 	}
-	defer f.Close()
 
-	_, err = fmt.Fprintf(f, "\n%s\n%s\n---\n", testID, snapshot)
-	return err
+	return snapshot, line, nil
 }
 
-func updateSnapshot(testID, snapshot, snapPath string) error {
-	// When t.Parallel a test can override another snapshot as we dump
-	// all snapshots
-	_m.Lock()
-	defer _m.Unlock()
-	f, err := os.OpenFile(snapPath, os.O_RDWR, 0o644)
+// addNewSnapshot adds the snapshot to the snapshot file, deferred buffers the write until flushed.
+func addNewSnapshot(testID, snapshot, snapPath string, meta *snapshotMetadata, deferred bool) error {
+	f, err := snapshotFiles.load(snapPath)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	tid := []byte(testID)
-	var updatedSnapFile bytes.Buffer
-	i, err := f.Stat()
-	if err == nil {
-		updatedSnapFile.Grow(int(i.Size()))
-	}
-
-	s := snapshotScanner(f)
-	for s.Scan() {
-		b := s.Bytes()
-		updatedSnapFile.Write(b)
-		updatedSnapFile.WriteByte('\n')
-		if !bytes.Equal(b, tid) {
-			continue
-		}
-
-		removeSnapshot(s)
 
-		// add new snapshot
-		updatedSnapFile.WriteString(snapshot)
-		updatedSnapFile.WriteByte('\n')
-		updatedSnapFile.Write(endSequenceByteSlice)
-		updatedSnapFile.WriteByte('\n')
-	}
+	return f.add(snapPath, testID, snapshot, meta, deferred)
+}
 
-	if err := s.Err(); err != nil {
+// updateSnapshot updates the snapshot in the snapshot file, deferred buffers the write until flushed.
+func updateSnapshot(testID, snapshot, snapPath string, meta *snapshotMetadata, deferred bool) error {
+	f, err := snapshotFiles.load(snapPath)
+	if err != nil {
 		return err
 	}
 
-	return overwriteFile(f, updatedSnapFile.Bytes())
-}
-
-func overwriteFile(f *os.File, b []byte) error {
-	f.Truncate(0)
-	f.Seek(0, io.SeekStart)
-	_, err := f.Write(b)
-	return err
-}
-
-func removeSnapshot(s *bufio.Scanner) {
-	for s.Scan() {
-		// skip until ---
-		if bytes.Equal(s.Bytes(), endSequenceByteSlice) {
-			break
-		}
-	}
+	return f.update(snapPath, testID, snapshot, meta, deferred)
 }
 
 func upsertStandaloneSnapshot(snapshot, snapPath string) error {
-	if err := os.MkdirAll(filepath.Dir(snapPath), 0o755); err != nil {
+	unlock, err := lockFile(snapPath)
+	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	data := []byte(snapshot)
+	// preserve the line endings of the existing snapshot
+	if prev, err := storageFor(snapPath).Read(snapPath); err == nil && usesCRLF(prev) {
+		data = toCRLF(data)
+	}
 
-	return os.WriteFile(snapPath, []byte(snapshot), 0o644)
+	return storageFor(snapPath).Write(snapPath, data)
 }
 
 func getPrevStandaloneSnapshot(snapPath string) (string, error) {
-	f, err := os.ReadFile(snapPath)
+	f, err := storageFor(snapPath).Read(snapPath)
 	if err != nil {
 		return "", errSnapNotFound
 	}
 
+	if usesCRLF(f) {
+		f = toLF(f)
+	}
+
 	return string(f), nil
 }
 
@@ -248,6 +291,7 @@ func getPrevStandaloneSnapshot(snapPath string) (string, error) {
 //   - if filename provided we return the filename with `.snap` extension
 //   - if extension provided we return the filename with `.snap` and the provided extension
 //   - if it's standalone snapshot we also append an integer (_%d) in the filename (even before `.snap`)
+//     or the snapshot name if provided with snaps.Name
 //
 // Returns the relative path of the caller and the snapshot path.
 func snapshotPath(c *Config, tName string, isStandalone bool) (string, string) {
@@ -260,6 +304,7 @@ func snapshotPath(c *Config, tName string, isStandalone bool) (string, string) {
 	}
 
 	snapPath := filepath.Join(dir, constructFilename(c, callerFilename, tName, isStandalone))
+	storages.register(snapPath, c.storage)
 	snapPathRel := snapPath
 	if !isTrimBathBuild {
 		snapPathRel, _ = filepath.Rel(filepath.Dir(callerFilename), snapPath)
@@ -280,29 +325,13 @@ func constructFilename(c *Config, callerFilename, tName string, isStandalone boo
 	}
 
 	if isStandalone {
-		filename += "_%d"
+		if c.name != "" {
+			filename += "_" + c.name
+		} else {
+			filename += "_%d"
+		}
 	}
 	filename += snapsExt + c.extension
 
 	return filename
 }
-
-func unescapeEndChars(s string) string {
-	ss := strings.Split(s, "\n")
-	for idx, s := range ss {
-		if s == "/-/-/-/" {
-			ss[idx] = endSequence
-		}
-	}
-	return strings.Join(ss, "\n")
-}
-
-func escapeEndChars(s string) string {
-	ss := strings.Split(s, "\n")
-	for idx, s := range ss {
-		if s == endSequence {
-			ss[idx] = "/-/-/-/"
-		}
-	}
-	return strings.Join(ss, "\n")
-}
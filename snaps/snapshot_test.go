@@ -1,7 +1,10 @@
 package snaps
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -53,6 +56,48 @@ func TestSyncRegistry(t *testing.T) {
 	})
 }
 
+func TestSyncRegistryNamed(t *testing.T) {
+	t.Run("should return named id", func(t *testing.T) {
+		registry := newRegistry()
+
+		id, err := registry.getNamedTestID("/file", "test", "after-login")
+
+		test.NoError(t, err)
+		test.Equal(t, "[test - after-login]", id)
+		test.Equal(t, set{"test - after-login": {}}, registry.cleanupNames["/file"])
+		// doesn't affect occurrences
+		test.Equal(t, "[test - 1]", registry.getTestID("/file", "test"))
+	})
+
+	t.Run("should error on duplicate names in the same test", func(t *testing.T) {
+		registry := newRegistry()
+
+		_, err := registry.getNamedTestID("/file", "test", "name")
+		test.NoError(t, err)
+
+		_, err = registry.getNamedTestID("/file", "test", "name")
+		test.True(t, errors.Is(err, errDuplicateName))
+
+		// same name in a different test is allowed
+		_, err = registry.getNamedTestID("/file", "test-v2", "name")
+		test.NoError(t, err)
+
+		// after reset e.g. on -count=2 the name can be used again
+		registry.reset("/file", "test")
+		_, err = registry.getNamedTestID("/file", "test", "name")
+		test.NoError(t, err)
+	})
+
+	t.Run("should validate names", func(t *testing.T) {
+		registry := newRegistry()
+
+		for _, name := range []string{"", "1", "10abc", "with space", "-name", "a]b"} {
+			_, err := registry.getNamedTestID("/file", "test", name)
+			test.True(t, errors.Is(err, errInvalidName))
+		}
+	})
+}
+
 func TestSyncStandaloneRegistry(t *testing.T) {
 	t.Run("should increment id on each call [concurrent safe]", func(t *testing.T) {
 		wg := sync.WaitGroup{}
@@ -117,6 +162,22 @@ func TestSyncStandaloneRegistry(t *testing.T) {
 	})
 }
 
+func TestSyncStandaloneRegistryNamed(t *testing.T) {
+	registry := newStandaloneRegistry()
+
+	test.True(t, registry.registerNamed("/file/my_file_response.snap"))
+	test.False(t, registry.registerNamed("/file/my_file_response.snap"))
+	test.True(t, registry.registerNamed("/file/my_file_request.snap"))
+
+	registry.reset("/file/my_file_response.snap")
+	test.True(t, registry.registerNamed("/file/my_file_response.snap"))
+
+	test.Equal(t, set{
+		"/file/my_file_response.snap": {},
+		"/file/my_file_request.snap":  {},
+	}, registry.cleanupNames)
+}
+
 func TestGetPrevSnapshot(t *testing.T) {
 	t.Run("should return errSnapNotFound", func(t *testing.T) {
 		snap, line, err := getPrevSnapshot("", "")
@@ -169,15 +230,15 @@ func TestGetPrevSnapshot(t *testing.T) {
 		},
 		{
 			description: "should ignore regex in testID and match correct snap",
-			testID:      "[.*]",
-			fileData:    "\n[my-test]\nwrong snap\n---\n\n[.*]\nmysnapshot\n---\n",
+			testID:      "[.* - 1]",
+			fileData:    "\n[my-test - 1]\nwrong snap\n---\n\n[.* - 1]\nmysnapshot\n---\n",
 			snap:        "mysnapshot",
 			line:        6,
 		},
 		{
 			description: "should ignore end chars (---) inside snapshot",
-			testID:      "[mock-test 1]",
-			fileData:    "\n[mock-test 1]\nmysnapshot\n---moredata\n---\n",
+			testID:      "[mock-test - 1]",
+			fileData:    "\n[mock-test - 1]\nmysnapshot\n---moredata\n---\n",
 			snap:        "mysnapshot\n---moredata",
 			line:        2,
 		},
@@ -199,8 +260,8 @@ func TestGetPrevSnapshot(t *testing.T) {
 func TestAddNewSnapshot(t *testing.T) {
 	snapPath := filepath.Join(t.TempDir(), "__snapshots__/mock-test.snap")
 
-	test.NoError(t, addNewSnapshot("[mock-id]", "my-snap", snapPath))
-	test.Equal(t, "\n[mock-id]\nmy-snap\n---\n", test.GetFileContent(t, snapPath))
+	test.NoError(t, addNewSnapshot("[mock-id - 1]", "my-snap", snapPath, nil, false))
+	test.Equal(t, "\n[mock-id - 1]\nmy-snap\n---\n", test.GetFileContent(t, snapPath))
 }
 
 func TestSnapshotPath(t *testing.T) {
@@ -249,6 +310,21 @@ func TestSnapshotPath(t *testing.T) {
 		)
 	})
 
+	t.Run("should use name for standalone snapshots", func(t *testing.T) {
+		snapPath, snapPathRel := snapshotPathWrapper(&Config{
+			name:      "response",
+			snapsDir:  "my_snapshot_dir",
+			extension: ".json",
+		}, "TestX/sub", true)
+
+		test.HasSuffix(
+			t,
+			snapPath,
+			filepath.FromSlash("/snaps/my_snapshot_dir/TestX_sub_response.snap.json"),
+		)
+		test.Equal(t, filepath.FromSlash("my_snapshot_dir/TestX_sub_response.snap.json"), snapPathRel)
+	})
+
 	t.Run("should add extension to filename", func(t *testing.T) {
 		snapPath, snapPathRel := snapshotPathWrapper(&Config{
 			filename:  "my_file",
@@ -341,7 +417,7 @@ string hello world 1 3 2
 	snapPath := test.CreateTempFile(t, mockSnap)
 	newSnapshot := "int(1250)\nstring new value"
 
-	test.NoError(t, updateSnapshot("[Test_3/TestSimple - 1]", newSnapshot, snapPath))
+	test.NoError(t, updateSnapshot("[Test_3/TestSimple - 1]", newSnapshot, snapPath, nil, false))
 	test.Equal(t, updatedSnap, test.GetFileContent(t, snapPath))
 }
 
@@ -350,15 +426,111 @@ func TestEscapeEndChars(t *testing.T) {
 		snapPath := filepath.Join(t.TempDir(), "__snapshots__/mock-test.snap")
 		snapshot := defaultConfig.takeSnapshot([]any{"my-snap", endSequence})
 
-		test.NoError(t, addNewSnapshot("[mock-id]", snapshot, snapPath))
-		test.Equal(t, "\n[mock-id]\nmy-snap\n/-/-/-/\n---\n", test.GetFileContent(t, snapPath))
+		test.NoError(t, addNewSnapshot("[mock-id - 1]", snapshot, snapPath, nil, false))
+		test.Equal(t, "# format: 2\n\n[mock-id - 1]\nmy-snap\n\\---\n---\n", test.GetFileContent(t, snapPath))
 	})
 
 	t.Run("should not escape --- if not end chars", func(t *testing.T) {
 		snapPath := filepath.Join(t.TempDir(), "__snapshots__/mock-test.snap")
 		snapshot := defaultConfig.takeSnapshot([]any{"my-snap---", endSequence})
 
-		test.NoError(t, addNewSnapshot("[mock-id]", snapshot, snapPath))
-		test.Equal(t, "\n[mock-id]\nmy-snap---\n/-/-/-/\n---\n", test.GetFileContent(t, snapPath))
+		test.NoError(t, addNewSnapshot("[mock-id - 1]", snapshot, snapPath, nil, false))
+		test.Equal(t, "# format: 2\n\n[mock-id - 1]\nmy-snap---\n\\---\n---\n", test.GetFileContent(t, snapPath))
 	})
+
+	t.Run("should not escape lines that are not test ids", func(t *testing.T) {
+		snapPath := filepath.Join(t.TempDir(), "__snapshots__/mock-test.snap")
+		snapshot := defaultConfig.takeSnapshot([]any{"my-snap---", "[not-a-header"})
+
+		test.NoError(t, addNewSnapshot("[mock-id - 1]", snapshot, snapPath, nil, false))
+		test.Equal(t, "\n[mock-id - 1]\nmy-snap---\n[not-a-header\n---\n", test.GetFileContent(t, snapPath))
+	})
+
+	t.Run("should not escape ini or log lines or migrate legacy files for them", func(t *testing.T) {
+		snapPath := test.CreateTempFile(t, "\n[mock-id - 1]\nvalue\n---\n")
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+		snapshot := "[section]\nkey = 1\n[INFO] started [worker - 1]\n[worker - 1]]"
+
+		test.False(t, needsEscaping(snapshot))
+		test.NoError(t, addNewSnapshot("[mock-id - 2]", snapshot, snapPath, nil, false))
+		test.Equal(
+			t,
+			"\n[mock-id - 1]\nvalue\n---\n\n[mock-id - 2]\n"+snapshot+"\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+
+		snap, line, err := getPrevSnapshot("[mock-id - 2]", snapPath)
+		test.NoError(t, err)
+		test.Equal(t, snapshot, snap)
+		test.Equal(t, 6, line)
+	})
+
+	t.Run("should escape reversibly", func(t *testing.T) {
+		for _, snapshot := range []string{
+			"---",
+			"/-/-/-/",
+			"\\---",
+			"[TestFoo - 1]",
+			"\\\\[TestFoo - 1]",
+			"\\not-a-marker",
+			"value\n---\n[Test]\n/-/-/-/\n\\---",
+		} {
+			escaped := escapeSnapshot(snapshot, escapedFormat)
+
+			for _, l := range strings.Split(escaped, "\n") {
+				test.False(t, l == endSequence || isTestIDLine([]byte(l)))
+			}
+			test.Equal(t, snapshot, unescapeSnapshot(escaped, escapedFormat))
+		}
+	})
+
+	t.Run("should read legacy escaped files", func(t *testing.T) {
+		snapPath := test.CreateTempFile(t, "\n[mock-id - 1]\nmy-snap\n/-/-/-/\n---\n")
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		snap, _, err := getPrevSnapshot("[mock-id - 1]", snapPath)
+		test.NoError(t, err)
+		test.Equal(t, "my-snap\n---", snap)
+	})
+
+	t.Run("should migrate legacy files when needed", func(t *testing.T) {
+		snapPath := test.CreateTempFile(t, "\n[mock-id - 1]\nmy-snap\n/-/-/-/\n---\n\n[mock-id - 2]\nvalue\n---\n")
+		t.Cleanup(func() { snapshotFiles.invalidate(snapPath) })
+
+		test.NoError(t, updateSnapshot("[mock-id - 2]", "updated", snapPath, nil, false))
+		test.Equal(
+			t,
+			"\n[mock-id - 1]\nmy-snap\n/-/-/-/\n---\n\n[mock-id - 2]\nupdated\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+
+		test.NoError(t, updateSnapshot("[mock-id - 2]", "[TestFoo - 1]\n/-/-/-/", snapPath, nil, false))
+		test.Equal(
+			t,
+			"# format: 2\n\n[mock-id - 1]\nmy-snap\n\\---\n---\n\n[mock-id - 2]\n\\[TestFoo - 1]\n/-/-/-/\n---\n",
+			test.GetFileContent(t, snapPath),
+		)
+
+		for id, expected := range map[string]string{
+			"[mock-id - 1]": "my-snap\n---",
+			"[mock-id - 2]": "[TestFoo - 1]\n/-/-/-/",
+		} {
+			snap, _, err := getPrevSnapshot(id, snapPath)
+			test.NoError(t, err)
+			test.Equal(t, expected, snap)
+		}
+	})
+}
+
+func TestStandaloneSnapshotCRLF(t *testing.T) {
+	snapPath := filepath.Join(t.TempDir(), "__snapshots__", "TestCRLF_1.snap")
+	test.NoError(t, os.MkdirAll(filepath.Dir(snapPath), 0o755))
+	test.NoError(t, os.WriteFile(snapPath, []byte("line 1\r\nline 2\r\n"), 0o644))
+
+	snap, err := getPrevStandaloneSnapshot(snapPath)
+	test.NoError(t, err)
+	test.Equal(t, "line 1\nline 2\n", snap)
+
+	test.NoError(t, upsertStandaloneSnapshot("updated\nvalue\n", snapPath))
+	test.Equal(t, "updated\r\nvalue\r\n", test.GetFileContent(t, snapPath))
 }
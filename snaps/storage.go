@@ -0,0 +1,341 @@
+package snaps
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	errReadOnlyStorage = errors.New("storage is read-only")
+	storages           = newStorageRegistry()
+	defaultStorage     = osStorage{}
+)
+
+// Storage is the backend where snapshot files are read from and written to.
+//
+// Paths are the snapshot file paths as resolved by go-snaps, see snaps.Dir and snaps.Filename.
+// The contents are always in the .snap format, so go-snaps takes care of parsing and formatting the snapshots.
+type Storage interface {
+	// Read returns the contents of the snapshot file.
+	// If the file doesn't exist the returned error must wrap fs.ErrNotExist.
+	Read(path string) ([]byte, error)
+	// Write replaces the contents of the snapshot file, creating it if it doesn't exist.
+	Write(path string, data []byte) error
+	// List returns the names of the files, excluding directories, inside dir.
+	List(dir string) ([]string, error)
+	// Delete removes the snapshot file.
+	Delete(path string) error
+}
+
+// syncStorageRegistry keeps track of the storage each snapshot file is using, and of every storage
+// used inside each directory, so snapshots can be read and cleaned from the correct storage.
+type syncStorageRegistry struct {
+	files map[string]Storage
+	dirs  map[string][]Storage
+	sync.RWMutex
+}
+
+func newStorageRegistry() *syncStorageRegistry {
+	return &syncStorageRegistry{
+		files:   make(map[string]Storage),
+		dirs:    make(map[string][]Storage),
+		RWMutex: sync.RWMutex{},
+	}
+}
+
+// register records the storage of the snapshot file, a nil storage is the OS filesystem.
+func (s *syncStorageRegistry) register(snapPath string, storage Storage) {
+	if storage == nil {
+		storage = defaultStorage
+	}
+
+	s.Lock()
+	prev, ok := s.files[snapPath]
+	s.files[snapPath] = storage
+	dir := filepath.Dir(snapPath)
+	if !slices.ContainsFunc(s.dirs[dir], func(st Storage) bool { return sameStorage(st, storage) }) {
+		s.dirs[dir] = append(s.dirs[dir], storage)
+	}
+	s.Unlock()
+
+	// the parsed snapshot file was read from the previous storage, e.g. when a test running again
+	// with -count uses a new storage
+	if ok && !sameStorage(prev, storage) {
+		snapshotFiles.invalidate(snapPath)
+	}
+}
+
+// get returns the storage registered for the snapshot file, defaulting to the OS filesystem.
+//
+// Files derived from a snapshot file e.g. blobs are resolved with the storage of the snapshot file.
+func (s *syncStorageRegistry) get(snapPath string) Storage {
+	s.RLock()
+	defer s.RUnlock()
+
+	if storage, ok := s.files[snapPath]; ok {
+		return storage
+	}
+
+	return defaultStorage
+}
+
+// getDir returns every storage used for snapshot files inside the directory, defaulting to the OS filesystem.
+func (s *syncStorageRegistry) getDir(dir string) []Storage {
+	s.RLock()
+	defer s.RUnlock()
+
+	if storages, ok := s.dirs[dir]; ok {
+		return slices.Clone(storages)
+	}
+
+	return []Storage{defaultStorage}
+}
+
+// sameStorage reports whether a and b are the same storage, without panicking on non comparable storages.
+func sameStorage(a, b Storage) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if !reflect.TypeOf(a).Comparable() {
+		return reflect.DeepEqual(a, b)
+	}
+
+	return a == b
+}
+
+func storageFor(snapPath string) Storage {
+	return storages.get(snapPath)
+}
+
+// osStorage stores snapshots on the OS filesystem, it is the default Storage.
+type osStorage struct{}
+
+func (osStorage) Read(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Write writes data to a temporary file which then renames to path,
+// so the snapshot file is never left half-written.
+func (osStorage) Write(path string, data []byte) error {
+	return writeFile(path, data)
+}
+
+// writeFile atomically replaces the file at path with data, keeping the mode of an existing file.
+// New files are created with 0o644 minus the umask.
+func writeFile(path string, data []byte) error {
+	mode := 0o644 &^ umask
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, ".go-snaps-tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// Lock acquires an advisory lock on path shared across processes,
+// for snapshot directories used by multiple packages. It's a no-op on platforms without flock.
+func (osStorage) Lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	return flock(lockPath(path))
+}
+
+func (osStorage) List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, e := range entries {
+		// lock files of snapshot files being written are not snapshot files
+		if !e.IsDir() && !isLockFile(e.Name()) {
+			files = append(files, e.Name())
+		}
+	}
+
+	return files, nil
+}
+
+func (osStorage) Delete(path string) error {
+	return os.Remove(path)
+}
+
+// MemoryStorage stores snapshots in memory. It can be used for testing helpers built on top of go-snaps
+// without touching the filesystem.
+type MemoryStorage struct {
+	files map[string][]byte
+	sync.RWMutex
+}
+
+// NewMemoryStorage returns an empty in-memory Storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		files:   make(map[string][]byte),
+		RWMutex: sync.RWMutex{},
+	}
+}
+
+func (m *MemoryStorage) Read(path string) ([]byte, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	data, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return slices.Clone(data), nil
+}
+
+func (m *MemoryStorage) Write(path string, data []byte) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.files[filepath.Clean(path)] = slices.Clone(data)
+	return nil
+}
+
+func (m *MemoryStorage) List(dir string) ([]string, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	dir = filepath.Clean(dir)
+	files := []string{}
+	for path := range m.files {
+		if filepath.Dir(path) == dir {
+			files = append(files, filepath.Base(path))
+		}
+	}
+	slices.Sort(files)
+
+	return files, nil
+}
+
+func (m *MemoryStorage) Delete(path string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	path = filepath.Clean(path)
+	if _, ok := m.files[path]; !ok {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	delete(m.files, path)
+
+	return nil
+}
+
+// fsStorage serves snapshots from a read-only fs.FS
+type fsStorage struct {
+	fsys fs.FS
+	root string
+}
+
+/*
+NewFSStorage returns a read-only Storage serving snapshots from fsys e.g. an embed.FS,
+so snapshots can be used in hermetic builds.
+
+	//go:embed __snapshots__
+	var snapshots embed.FS
+
+	snaps.WithConfig(snaps.StorageBackend(snaps.NewFSStorage(snapshots))).MatchSnapshot(t, value)
+
+Snapshot paths are resolved relative to the current working directory, which for `go test` is the
+package directory, same as the paths embedded with go:embed.
+
+Creating, updating or removing snapshots returns an error.
+*/
+func NewFSStorage(fsys fs.FS) Storage {
+	root, _ := os.Getwd()
+
+	return &fsStorage{fsys: fsys, root: root}
+}
+
+func (f *fsStorage) name(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(f.root, path)
+		if err != nil {
+			return "", err
+		}
+		path = rel
+	}
+
+	name := filepath.ToSlash(filepath.Clean(path))
+	if !fs.ValidPath(name) || strings.HasPrefix(name, "../") {
+		return "", &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return name, nil
+}
+
+func (f *fsStorage) Read(path string) ([]byte, error) {
+	name, err := f.name(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.ReadFile(f.fsys, name)
+}
+
+func (f *fsStorage) Write(path string, _ []byte) error {
+	return &fs.PathError{Op: "write", Path: path, Err: errReadOnlyStorage}
+}
+
+func (f *fsStorage) List(dir string) ([]string, error) {
+	name, err := f.name(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(f.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, e := range entries {
+		// lock files of snapshot files being written are not snapshot files
+		if !e.IsDir() && !isLockFile(e.Name()) {
+			files = append(files, e.Name())
+		}
+	}
+
+	return files, nil
+}
+
+func (f *fsStorage) Delete(path string) error {
+	return &fs.PathError{Op: "remove", Path: path, Err: errReadOnlyStorage}
+}
@@ -0,0 +1,205 @@
+package snaps
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gkampitakis/go-snaps/internal/test"
+)
+
+const storageFilename = "storage_test.snap"
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage()
+
+	_, err := s.Read("/snaps/a.snap")
+	test.True(t, errors.Is(err, fs.ErrNotExist))
+
+	test.NoError(t, s.Write("/snaps/a.snap", []byte("a")))
+	test.NoError(t, s.Write("/snaps/b.snap", []byte("b")))
+	test.NoError(t, s.Write("/snaps/nested/c.snap", []byte("c")))
+
+	b, err := s.Read("/snaps/a.snap")
+	test.NoError(t, err)
+	test.Equal(t, "a", string(b))
+
+	files, err := s.List("/snaps")
+	test.NoError(t, err)
+	test.Equal(t, []string{"a.snap", "b.snap"}, files)
+
+	test.NoError(t, s.Delete("/snaps/b.snap"))
+	test.True(t, errors.Is(s.Delete("/snaps/b.snap"), fs.ErrNotExist))
+}
+
+func TestFSStorage(t *testing.T) {
+	dir, _ := os.Getwd()
+	s := NewFSStorage(fstest.MapFS{
+		"__snapshots__/a.snap":        {Data: []byte("a")},
+		"__snapshots__/nested/b.snap": {Data: []byte("b")},
+	})
+
+	b, err := s.Read(filepath.Join(dir, "__snapshots__", "a.snap"))
+	test.NoError(t, err)
+	test.Equal(t, "a", string(b))
+
+	_, err = s.Read(filepath.Join(dir, "__snapshots__", "missing.snap"))
+	test.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = s.Read(filepath.Join(filepath.Dir(dir), "a.snap"))
+	test.True(t, errors.Is(err, fs.ErrNotExist))
+
+	files, err := s.List(filepath.Join(dir, "__snapshots__"))
+	test.NoError(t, err)
+	test.Equal(t, []string{"a.snap"}, files)
+
+	test.True(t, errors.Is(s.Write("a.snap", nil), errReadOnlyStorage))
+	test.True(t, errors.Is(s.Delete("a.snap"), errReadOnlyStorage))
+}
+
+func TestStorageBackend(t *testing.T) {
+	t.Run("should create and update snapshots in storage", func(t *testing.T) {
+		snapPath := setupSnapshot(t, storageFilename, false, "true")
+		storage := NewMemoryStorage()
+		c := WithConfig(StorageBackend(storage))
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+
+		c.MatchSnapshot(mockT, "hello world")
+		testsRegistry = newRegistry()
+		c.MatchSnapshot(mockT, "bye world")
+
+		b, err := storage.Read(snapPath)
+		test.NoError(t, err)
+		test.Equal(t, "\n[mock-name - 1]\nbye world\n---\n", string(b))
+		test.Equal(t, 1, testEvents.items[added])
+		test.Equal(t, 1, testEvents.items[updated])
+
+		_, err = os.Stat(snapPath)
+		test.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("should create standalone snapshots in storage", func(t *testing.T) {
+		snapPath := setupSnapshot(t, "mock-name_1.snap", false)
+		storage := NewMemoryStorage()
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+
+		WithConfig(StorageBackend(storage)).MatchStandaloneSnapshot(mockT, "hello world")
+
+		b, err := storage.Read(snapPath)
+		test.NoError(t, err)
+		test.Equal(t, "hello world", string(b))
+	})
+
+	t.Run("should clean obsolete snapshots in storage", func(t *testing.T) {
+		snapPath := setupSnapshot(t, storageFilename, false)
+		dir := filepath.Dir(snapPath)
+		storage := NewMemoryStorage()
+		storages.register(snapPath, storage)
+
+		test.NoError(t, storage.Write(snapPath, []byte(
+			"\n[TestA - 1]\nvalue\n---\n\n[TestB - 1]\nvalue\n---\n",
+		)))
+		test.NoError(t, storage.Write(filepath.Join(dir, "obsolete.snap"), []byte("")))
+
+		registry := map[string]map[string]int{snapPath: {"TestA": 1}}
+		obsolete, used, _ := examineFiles(registry, nil, testFilter{}, true)
+		test.Equal(t, []string{filepath.Join(dir, "obsolete.snap")}, obsolete)
+
+		obsoleteTests, _, err := examineSnaps(registry, nil, used, testFilter{}, 1, true, false, nil)
+		test.NoError(t, err)
+		test.Equal(t, []string{"TestB - 1"}, obsoleteTests)
+
+		b, _ := storage.Read(snapPath)
+		test.Equal(t, "\n[TestA - 1]\nvalue\n---\n", string(b))
+		files, _ := storage.List(dir)
+		test.Equal(t, []string{storageFilename}, files)
+	})
+
+	t.Run("should not use the storage of other snapshot files in the same directory", func(t *testing.T) {
+		snapPath := setupSnapshot(t, storageFilename, false)
+		defaultPath := setupSnapshot(t, "storage_default_test.snap", false)
+		storage := NewMemoryStorage()
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+
+		WithConfig(StorageBackend(storage)).MatchSnapshot(mockT, "hello world")
+		WithConfig(Filename("storage_default_test")).MatchSnapshot(mockT, "bye world")
+
+		b, err := storage.Read(snapPath)
+		test.NoError(t, err)
+		test.Equal(t, "\n[mock-name - 1]\nhello world\n---\n", string(b))
+		_, err = storage.Read(defaultPath)
+		test.True(t, errors.Is(err, fs.ErrNotExist))
+		test.Equal(t, "\n[mock-name - 1]\nbye world\n---\n", test.GetFileContent(t, defaultPath))
+	})
+
+	t.Run("should clean obsolete snapshots in every storage of a directory", func(t *testing.T) {
+		dir := t.TempDir()
+		memPath := filepath.Join(dir, "mem_test.snap")
+		diskPath := filepath.Join(dir, "disk_test.snap")
+		storage := NewMemoryStorage()
+		t.Cleanup(func() { storages = newStorageRegistry() })
+		storages.register(memPath, storage)
+		storages.register(diskPath, nil)
+
+		test.NoError(t, storage.Write(memPath, []byte("\n[TestA - 1]\na\n---\n")))
+		test.NoError(t, storage.Write(filepath.Join(dir, "mem_obsolete.snap"), []byte("")))
+		test.NoError(t, os.WriteFile(diskPath, []byte("\n[TestB - 1]\nb\n---\n"), 0o644))
+		test.NoError(t, os.WriteFile(filepath.Join(dir, "disk_obsolete.snap"), []byte(""), 0o644))
+
+		registry := map[string]map[string]int{memPath: {"TestA": 1}, diskPath: {"TestB": 1}}
+		obsolete, used, _ := examineFiles(registry, nil, testFilter{}, true)
+
+		slices.Sort(obsolete)
+		slices.Sort(used)
+		test.Equal(t, []string{filepath.Join(dir, "disk_obsolete.snap"), filepath.Join(dir, "mem_obsolete.snap")}, obsolete)
+		test.Equal(t, []string{diskPath, memPath}, used)
+		files, _ := storage.List(dir)
+		test.Equal(t, []string{"mem_test.snap"}, files)
+		entries, _ := os.ReadDir(dir)
+		test.Equal(t, 1, len(entries))
+	})
+
+	t.Run("should not use the snapshots of a previous storage of the snapshot file", func(t *testing.T) {
+		snapPath := setupSnapshot(t, storageFilename, false)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockLog = func(...any) {}
+
+		// same as a test running again with -count=2
+		for range 2 {
+			storage := NewMemoryStorage()
+			testsRegistry = newRegistry()
+			WithConfig(StorageBackend(storage)).MatchSnapshot(mockT, "hello world")
+
+			b, err := storage.Read(snapPath)
+			test.NoError(t, err)
+			test.Equal(t, "\n[mock-name - 1]\nhello world\n---\n", string(b))
+		}
+		test.Equal(t, 2, testEvents.items[added])
+	})
+
+	t.Run("should read snapshots from fs.FS", func(t *testing.T) {
+		setupSnapshot(t, storageFilename, true)
+
+		mockT := test.NewMockTestingT(t)
+		mockT.MockError = func(args ...any) {
+			test.Contains(t, args[0].(string), "__snapshots__/storage_test.snap:2")
+		}
+
+		WithConfig(StorageBackend(NewFSStorage(fstest.MapFS{
+			"__snapshots__/" + storageFilename: {Data: []byte("\n[mock-name - 1]\nhello world\n---\n")},
+		}))).MatchSnapshot(mockT, "bye world")
+
+		test.Equal(t, 1, testEvents.items[erred])
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package snaps
+
+import "io/fs"
+
+// umask is zero on platforms without umask, where only the read-only bit of file modes is used.
+var umask fs.FileMode
@@ -0,0 +1,16 @@
+//go:build unix
+
+package snaps
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// umask is the process umask, read once on init as it can only be read by setting it.
+var umask = func() fs.FileMode {
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+
+	return fs.FileMode(mask)
+}()
@@ -1,10 +1,8 @@
 package snaps
 
 import (
-	"bufio"
+	"bytes"
 	"errors"
-	"io"
-	"math"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -18,11 +16,15 @@ import (
 
 var (
 	errSnapNotFound = errors.New("snapshot not found")
-	isCI            = ciinfo.IsCI
-	updateVAR       = os.Getenv("UPDATE_SNAPS")
-	shouldClean     = updateVAR == "always" || (updateVAR == "true" && !isCI) ||
-		(updateVAR == "clean" && !isCI)
-	isTrimBathBuild = trimPathBuild()
+	errInvalidName  = errors.New(
+		"invalid snapshot name, must start with a letter and contain only letters, digits, '_', '.' or '-'",
+	)
+	errDuplicateName = errors.New("snapshot name used more than once")
+	// errUnsupportedFormat is returned for snapshot files written by a newer version of go-snaps
+	errUnsupportedFormat = errors.New("unsupported snapshot format")
+	isCI                 = ciinfo.IsCI
+	updateVAR            = os.Getenv("UPDATE_SNAPS")
+	isTrimBathBuild      = trimPathBuild()
 )
 
 const (
@@ -109,45 +111,20 @@ func baseCaller(skip int) (string, int) {
 	}
 }
 
-// snapshotScanner returns a new *bufio.Scanner with a `MaxScanTokenSize == math.MaxInt` to read from r.
-func snapshotScanner(r io.Reader) *bufio.Scanner {
-	s := bufio.NewScanner(r)
-	s.Buffer([]byte{}, math.MaxInt)
-	return s
+// usesCRLF reports whether b uses CRLF line endings, based on its first line.
+func usesCRLF(b []byte) bool {
+	i := bytes.IndexByte(b, '\n')
+	return i > 0 && b[i-1] == '\r'
 }
 
-// shouldUpdate determines whether snapshots should be updated
-func shouldUpdate(u *bool) bool {
-	if updateVAR == "always" {
-		return true
-	}
-
-	if isCI {
-		return false
-	}
-
-	if u != nil {
-		return *u
-	}
-
-	return updateVAR == "true"
+// toLF converts CRLF line endings to LF.
+func toLF(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
 }
 
-// shouldCreate determines whether snapshots should be created
-func shouldCreate(u *bool) bool {
-	if updateVAR == "always" {
-		return true
-	}
-
-	if isCI {
-		return false
-	}
-
-	if u != nil {
-		return *u
-	}
-
-	return true
+// toCRLF converts LF line endings to CRLF.
+func toCRLF(b []byte) []byte {
+	return bytes.ReplaceAll(toLF(b), []byte("\n"), []byte("\r\n"))
 }
 
 // trimPathBuild checks if the build has trimpath setting true